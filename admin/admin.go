@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/levels"
+)
+
+// Logger is the state of a logger served by the handler
+type Logger struct {
+	// dotted name of the logger; empty for the default logger
+	Name string `json:"name"`
+	// level set on the logger, which may be "inherit"
+	Level string `json:"level"`
+	// level that the logger uses
+	Effective string `json:"effective"`
+	// time when the level reverts, if it was changed temporarily
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Change is a request to change the level of a logger
+type Change struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	// how long until the level reverts, as in "10m"; empty to keep it
+	TTL string `json:"ttl,omitempty"`
+}
+
+// revert is a pending change back to the previous level of a logger
+type revert struct {
+	level   int
+	expires time.Time
+	timer   *time.Timer
+}
+
+// Handler is an http.Handler that lists the hierarchical loggers and their
+// levels on GET, and changes the level of a logger on PUT or POST. Only loggers
+// with a Level can be changed, which includes all loggers returned by Get and
+// captainslog.NewLogger.
+type Handler struct {
+	mutex   sync.Mutex
+	reverts map[string]*revert
+}
+
+// NewHandler returns a handler for the levels of the hierarchical loggers
+func NewHandler() *Handler {
+	return &Handler{
+		reverts: map[string]*revert{},
+	}
+}
+
+// ServeHTTP lists loggers or changes a level
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w)
+	case http.MethodPut, http.MethodPost:
+		h.change(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Loggers returns the state of the default logger and all hierarchical loggers
+func (h *Handler) Loggers() []Logger {
+	names := append([]string{""}, captainslog.Names()...)
+	loggers := make([]Logger, len(names))
+	for i, name := range names {
+		loggers[i] = h.state(name)
+	}
+
+	return loggers
+}
+
+// Set changes the level of a logger, reverting it after a TTL if it is positive
+func (h *Handler) Set(name string, level int, ttl time.Duration) error {
+	log := captainslog.Get(name)
+	if log.Level == nil {
+		return captainslog.ErrNoLevel
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	pending := h.reverts[name]
+	if pending != nil {
+		pending.timer.Stop()
+		delete(h.reverts, name)
+	}
+	if ttl > 0 {
+		// revert to the level before the first of overlapping temporary changes
+		previous := log.GetLevel()
+		if pending != nil {
+			previous = pending.level
+		}
+		pending = &revert{level: previous, expires: time.Now().Add(ttl)}
+		pending.timer = time.AfterFunc(ttl, func() {
+			h.expire(name, pending)
+		})
+		h.reverts[name] = pending
+	}
+	log.SetLevel(level)
+
+	return nil
+}
+
+// expire reverts a temporary change unless it was replaced
+func (h *Handler) expire(name string, pending *revert) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.reverts[name] != pending {
+		return
+	}
+	delete(h.reverts, name)
+	captainslog.Get(name).SetLevel(pending.level)
+}
+
+// list writes the state of all loggers
+func (h *Handler) list(w http.ResponseWriter) {
+	respond(w, http.StatusOK, h.Loggers())
+}
+
+// change applies a change to the level of a logger and writes its new state
+func (h *Handler) change(w http.ResponseWriter, r *http.Request) {
+	change := Change{}
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+
+		return
+	}
+	level, err := levels.Parse(change.Level)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	ttl := time.Duration(0)
+	if len(change.TTL) > 0 {
+		ttl, err = time.ParseDuration(change.TTL)
+		if err != nil || ttl < 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %q", change.TTL), http.StatusBadRequest)
+
+			return
+		}
+	}
+	if !exists(change.Name) {
+		http.Error(w, fmt.Sprintf("unknown logger %q", change.Name), http.StatusNotFound)
+
+		return
+	}
+
+	if err := h.Set(change.Name, level, ttl); err != nil {
+		http.Error(w, fmt.Sprintf("cannot change logger %q: %s", change.Name, err), http.StatusConflict)
+
+		return
+	}
+	respond(w, http.StatusOK, h.state(change.Name))
+}
+
+// state returns the state of a logger
+func (h *Handler) state(name string) Logger {
+	log := captainslog.Get(name)
+	state := Logger{
+		Name:      name,
+		Level:     levels.Name(log.GetLevel()),
+		Effective: levels.Name(log.EffectiveLevel()),
+	}
+
+	h.mutex.Lock()
+	if pending, ok := h.reverts[name]; ok {
+		expires := pending.expires
+		state.Expires = &expires
+	}
+	h.mutex.Unlock()
+
+	return state
+}
+
+// respond writes a value as JSON
+func respond(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// exists returns true if a logger is the default one or a hierarchical one
+func exists(name string) bool {
+	if len(name) == 0 {
+		return true
+	}
+	names := captainslog.Names()
+	i := sort.SearchStrings(names, name)
+
+	return i < len(names) && names[i] == name
+}
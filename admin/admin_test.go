@@ -0,0 +1,200 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/admin"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/preflight"
+)
+
+func TestList(test *testing.T) {
+	t := preflight.Unit(test)
+
+	captainslog.Get("borg.cube").SetLevel(levels.Warn)
+	server := httptest.NewServer(admin.NewHandler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	t.Expect(err).Is().Nil()
+	defer res.Body.Close()
+
+	t.Expect(res.StatusCode).Equals(http.StatusOK)
+	t.Expect(res.Header.Get("Content-Type")).Equals("application/json")
+
+	loggers := []admin.Logger{}
+	t.Expect(json.NewDecoder(res.Body).Decode(&loggers)).Is().Nil()
+	t.Expect(loggers[0].Name).Equals("")
+	t.Expect(find(loggers, "borg")).Equals(admin.Logger{
+		Name:      "borg",
+		Level:     "inherit",
+		Effective: levels.Name(captainslog.Default().EffectiveLevel()),
+	})
+	t.Expect(find(loggers, "borg.cube")).Equals(admin.Logger{
+		Name:      "borg.cube",
+		Level:     "warn",
+		Effective: "warn",
+	})
+}
+
+func TestChange(test *testing.T) {
+	t := preflight.Unit(test)
+
+	log := captainslog.Get("borg.sphere")
+	server := httptest.NewServer(admin.NewHandler())
+	defer server.Close()
+
+	res := request(t, server, http.MethodPut, `{"name": "borg.sphere", "level": "TRACE"}`)
+	defer res.Body.Close()
+	t.Expect(res.StatusCode).Equals(http.StatusOK)
+
+	state := admin.Logger{}
+	t.Expect(json.NewDecoder(res.Body).Decode(&state)).Is().Nil()
+	t.Expect(state.Level).Equals("trace")
+	t.Expect(state.Expires).Is().Nil()
+	t.Expect(log.EffectiveLevel()).Equals(levels.Trace)
+}
+
+func TestTTL(test *testing.T) {
+	t := preflight.Unit(test)
+
+	log := captainslog.Get("borg.probe")
+	log.SetLevel(levels.Error)
+	server := httptest.NewServer(admin.NewHandler())
+	defer server.Close()
+
+	res := request(t, server, http.MethodPost, `{"name": "borg.probe", "level": "debug", "ttl": "50ms"}`)
+	defer res.Body.Close()
+
+	state := admin.Logger{}
+	t.Expect(json.NewDecoder(res.Body).Decode(&state)).Is().Nil()
+	t.Expect(state.Expires).Is().Not().Nil()
+	t.Expect(log.EffectiveLevel()).Equals(levels.Debug)
+
+	// a second temporary change should revert to the original level
+	res = request(t, server, http.MethodPost, `{"name": "borg.probe", "level": "trace", "ttl": "100ms"}`)
+	defer res.Body.Close()
+	t.Expect(log.EffectiveLevel()).Equals(levels.Trace)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for log.EffectiveLevel() != levels.Error && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Expect(log.EffectiveLevel()).Equals(levels.Error)
+}
+
+func TestChangeWhileLogging(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+	log := captainslog.NewLogger()
+	log.Stdout, log.Stderr = discard(t), discard(t)
+	captainslog.SetDefault(log)
+
+	server := httptest.NewServer(admin.NewHandler())
+	defer server.Close()
+
+	// changes should be safe while logging, which the race detector checks
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			captainslog.Trace("scan %d", i)
+			captainslog.Get("borg.scout").Trace("scan %d", i)
+		}
+	}()
+	for _, name := range []string{"", "borg.scout"} {
+		for _, level := range []string{"trace", "info"} {
+			res := request(t, server, http.MethodPut, `{"name": "`+name+`", "level": "`+level+`"}`)
+			res.Body.Close()
+			t.Expect(res.StatusCode).Equals(http.StatusOK)
+		}
+	}
+	<-done
+
+	t.Expect(log.GetLevel()).Equals(levels.Info)
+}
+
+func TestNoLevel(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+	captainslog.SetDefault(&captainslog.Logger{})
+
+	// loggers without a level should not be changed
+	handler := admin.NewHandler()
+	t.Expect(handler.Set("", levels.Info, 0)).Equals(captainslog.ErrNoLevel)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	res := request(t, server, http.MethodPut, `{"name": "", "level": "info"}`)
+	res.Body.Close()
+	t.Expect(res.StatusCode).Equals(http.StatusConflict)
+	t.Expect(captainslog.Default().Level).Is().Nil()
+}
+
+func TestInvalid(test *testing.T) {
+	t := preflight.Unit(test)
+
+	captainslog.Get("borg.diamond")
+	server := httptest.NewServer(admin.NewHandler())
+	defer server.Close()
+
+	for body, status := range map[string]int{
+		`{"name": "borg.diamond"`:                                  http.StatusBadRequest,
+		`{"name": "borg.diamond", "level": "loud"}`:                http.StatusBadRequest,
+		`{"name": "borg.diamond", "level": "info", "ttl": "soon"}`: http.StatusBadRequest,
+		`{"name": "borg.tactical", "level": "info"}`:               http.StatusNotFound,
+	} {
+		res := request(t, server, http.MethodPut, body)
+		res.Body.Close()
+		t.Expect(res.StatusCode).Equals(status)
+	}
+
+	res := request(t, server, http.MethodDelete, "")
+	res.Body.Close()
+	t.Expect(res.StatusCode).Equals(http.StatusMethodNotAllowed)
+	t.Expect(res.Header.Get("Allow")).Equals("GET, PUT, POST")
+}
+
+/**
+ * Test Helpers
+ */
+
+func request(t *preflight.Test, server *httptest.Server, method string, body string) *http.Response {
+	req, err := http.NewRequest(method, server.URL, strings.NewReader(body))
+	t.Expect(err).Is().Nil()
+	res, err := http.DefaultClient.Do(req)
+	t.Expect(err).Is().Nil()
+
+	return res
+}
+
+func discard(t *preflight.Test) *os.File {
+	file, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	t.Expect(err).Is().Nil()
+	t.T.Cleanup(func() {
+		file.Close()
+	})
+
+	return file
+}
+
+func find(loggers []admin.Logger, name string) admin.Logger {
+	for _, log := range loggers {
+		if log.Name == name {
+			return log
+		}
+	}
+
+	return admin.Logger{}
+}
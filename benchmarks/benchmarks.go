@@ -12,6 +12,8 @@ import (
 
 	"vincent.click/pkg/captainslog/v2"
 	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/meta"
+	"vincent.click/pkg/captainslog/v2/msg"
 )
 
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -23,6 +25,10 @@ func main() {
 	results = append(results, runBenchmark("captainslog", benchmarkCaptainsLog))
 	results = append(results, runBenchmark("captainslog (json)", benchmarkCaptainsLogJSON))
 	results = append(results, runBenchmark("captainslog (minimal)", benchmarkCaptainsLogMinimal))
+	results = append(results, runBenchmark("captainslog (typed)", benchmarkCaptainsLogTyped))
+	results = append(results, runBenchmark("captainslog (typed json)", benchmarkCaptainsLogTypedJSON))
+	results = append(results, runBenchmark("captainslog (meta json)", benchmarkCaptainsLogMetadataJSON))
+	results = append(results, runBenchmark("captainslog (goroutine)", benchmarkCaptainsLogGoroutine))
 
 	for _, res := range results {
 		fmt.Println(res)
@@ -110,6 +116,93 @@ func benchmarkCaptainsLogMinimal(b *testing.B) {
 	})
 }
 
+func benchmarkCaptainsLogTyped(b *testing.B) {
+	out := createTemp(b)
+	defer out.Close()
+
+	log := captainslog.NewLogger()
+	log.Name = "benchmark"
+	log.HasColor = false
+	log.Stdout = out
+
+	b.RunParallel(func(i *testing.PB) {
+		for i.Next() {
+			log.Fields(
+				msg.String("a", "enterprise"),
+				msg.Int("b", rand.Int()),
+				msg.Float64("c", rand.Float64()),
+				msg.Bool("d", true),
+			).Info("starship enterprise")
+		}
+	})
+}
+
+func benchmarkCaptainsLogTypedJSON(b *testing.B) {
+	out := createTemp(b)
+	defer out.Close()
+
+	log := captainslog.NewLogger()
+	log.Name = "benchmark"
+	log.HasColor = false
+	log.Stdout = out
+	log.Format = format.JSON
+
+	b.RunParallel(func(i *testing.PB) {
+		for i.Next() {
+			log.Fields(
+				msg.String("a", "enterprise"),
+				msg.Int("b", rand.Int()),
+				msg.Float64("c", rand.Float64()),
+				msg.Bool("d", true),
+			).Info("starship enterprise")
+		}
+	})
+}
+
+func benchmarkCaptainsLogMetadataJSON(b *testing.B) {
+	out := createTemp(b)
+	defer out.Close()
+
+	log := captainslog.NewLogger().With(meta.Collect("benchmark", "test").Group("process"))
+	log.Name = "benchmark"
+	log.HasColor = false
+	log.Stdout = out
+	log.Format = format.JSON
+
+	b.RunParallel(func(i *testing.PB) {
+		for i.Next() {
+			log.Fields(
+				msg.String("a", "enterprise"),
+				msg.Int("b", rand.Int()),
+				msg.Float64("c", rand.Float64()),
+				msg.Bool("d", true),
+			).Info("starship enterprise")
+		}
+	})
+}
+
+func benchmarkCaptainsLogGoroutine(b *testing.B) {
+	out := createTemp(b)
+	defer out.Close()
+
+	log := captainslog.NewLogger()
+	log.Name = "benchmark"
+	log.HasColor = false
+	log.Stdout = out
+	log.Goroutine = true
+
+	b.RunParallel(func(i *testing.PB) {
+		for i.Next() {
+			log.Fields(
+				msg.String("a", "enterprise"),
+				msg.Int("b", rand.Int()),
+				msg.Float64("c", rand.Float64()),
+				msg.Bool("d", true),
+			).Info("starship enterprise")
+		}
+	})
+}
+
 func createTemp(b *testing.B) *os.File {
 	out, err := os.CreateTemp(os.TempDir(), "log")
 	if err != nil {
@@ -46,3 +46,14 @@ func getLastPart(text string, sep string) string {
 
 	return parts[len(parts)-1]
 }
+
+// PC returns the program counter of the n-th caller up the stack, which
+// identifies the call site without allocating memory
+func PC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) < 1 {
+		return 0
+	}
+
+	return pcs[0]
+}
@@ -1,6 +1,7 @@
 package caller_test
 
 import (
+	"runtime"
 	"testing"
 
 	"vincent.click/pkg/captainslog/v2/caller"
@@ -29,3 +30,17 @@ func TestShorten(test *testing.T) {
 	t.Expect(caller.Shorten(path, 11)).Equals("TestShort..")
 	t.Expect(caller.Shorten(path, 30)).Equals("caller_test.TestShorten")
 }
+
+func TestPC(test *testing.T) {
+	t := preflight.Unit(test)
+
+	// PC(1) should point into the calling function
+	this := "vincent.click/pkg/captainslog/v2/caller_test.TestPC"
+	frame, _ := runtime.CallersFrames([]uintptr{caller.PC(1)}).Next()
+	t.Expect(frame.Function).Equals(this)
+
+	// different call sites should have different program counters
+	first := caller.PC(1)
+	second := caller.PC(1)
+	t.Expect(first == second).Equals(false)
+}
@@ -0,0 +1,85 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the clock of the operating system
+var System Clock = system{}
+
+// system tells the time using time.Now
+type system struct{}
+
+// Now returns the current local time
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+// fixed always tells the same time
+type fixed struct {
+	time time.Time
+}
+
+// Fixed returns a clock that always tells the same time
+func Fixed(t time.Time) Clock {
+	return fixed{t}
+}
+
+// Now returns the fixed time
+func (c fixed) Now() time.Time {
+	return c.time
+}
+
+// stepping advances every time it is read
+type stepping struct {
+	mutex sync.Mutex
+	next  time.Time
+	step  time.Duration
+}
+
+// Stepping returns a clock that starts at a time and moves forward
+// by a step every time it is read
+func Stepping(start time.Time, step time.Duration) Clock {
+	return &stepping{
+		next: start,
+		step: step,
+	}
+}
+
+// Now returns the current time and advances the clock
+func (c *stepping) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.next
+	c.next = c.next.Add(c.step)
+
+	return now
+}
+
+// located tells the time of another clock in a location
+type located struct {
+	clock    Clock
+	location *time.Location
+}
+
+// In returns a clock that tells the time of another clock in a location
+func In(clock Clock, location *time.Location) Clock {
+	return located{clock, location}
+}
+
+// UTC returns a clock that tells the time of another clock in UTC
+func UTC(clock Clock) Clock {
+	return In(clock, time.UTC)
+}
+
+// Now returns the current time in the location
+func (c located) Now() time.Time {
+	return c.clock.Now().In(c.location)
+}
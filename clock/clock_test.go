@@ -0,0 +1,50 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/preflight"
+)
+
+var stardate = time.Date(2364, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestSystem(test *testing.T) {
+	t := preflight.Unit(test)
+
+	before := time.Now()
+	now := clock.System.Now()
+
+	t.Expect(now.Before(before)).Equals(false)
+}
+
+func TestFixed(test *testing.T) {
+	t := preflight.Unit(test)
+
+	c := clock.Fixed(stardate)
+
+	t.Expect(c.Now()).Equals(stardate)
+	t.Expect(c.Now()).Equals(stardate)
+}
+
+func TestStepping(test *testing.T) {
+	t := preflight.Unit(test)
+
+	c := clock.Stepping(stardate, time.Second)
+
+	t.Expect(c.Now()).Equals(stardate)
+	t.Expect(c.Now()).Equals(stardate.Add(time.Second))
+	t.Expect(c.Now()).Equals(stardate.Add(2 * time.Second))
+}
+
+func TestIn(test *testing.T) {
+	t := preflight.Unit(test)
+
+	pst := time.FixedZone("PST", -8*60*60)
+	c := clock.In(clock.Fixed(stardate), pst)
+
+	t.Expect(c.Now().Location()).Equals(pst)
+	t.Expect(c.Now().Hour()).Equals(19)
+	t.Expect(clock.UTC(c).Now().Location()).Equals(time.UTC)
+}
@@ -0,0 +1,400 @@
+package captainslog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/vmodule"
+)
+
+// EnvPrefix is the prefix of environment variables read by Config.LoadEnv
+const EnvPrefix = "CAPTAINSLOG_"
+
+// Config is a declarative configuration of a logger, which can be loaded
+// from JSON or environment variables
+type Config struct {
+	// name of the logger; leave empty to log the current function
+	Name string `json:"name,omitempty"`
+	// minimum level, such as "debug"
+	Level string `json:"level,omitempty"`
+	// whether to use colors regardless of the streams and environment;
+	// leave empty to detect whether they are supported
+	Color *bool `json:"color,omitempty"`
+	// one of "dark", "light", or "high-contrast"
+	Theme string `json:"theme,omitempty"`
+	// names of the levels: "lowercase", "uppercase", "short", or "letters",
+	// wrapped in brackets as in "[uppercase]" to bracket them
+	Labels string `json:"labels,omitempty"`
+	// width that level names are padded to, or 0 to leave them unpadded;
+	// leave empty for the width of the labels
+	LabelWidth *int `json:"labelWidth,omitempty"`
+	// one of "flat", "minimal", "json", or "pretty"
+	Format string `json:"format,omitempty"`
+	// one of "iso8601", "rfc3339", "unixmillis", or a layout string
+	TimeFormat string `json:"timeFormat,omitempty"`
+	// maximum caller name length to display
+	NameCutoff int `json:"nameCutoff,omitempty"`
+	// where trace, debug, and info messages are written: "stdout",
+	// "stderr", "discard", or the path of a file to append to
+	Stdout string `json:"stdout,omitempty"`
+	// where warnings and errors are written, like Stdout
+	Stderr string `json:"stderr,omitempty"`
+	// levels of hierarchical loggers by name
+	Levels map[string]string `json:"levels,omitempty"`
+	// rules that set the level by call site, as in "app/db=trace"
+	VModule string `json:"vmodule,omitempty"`
+	// whether to add the ID of the logging goroutine to every message
+	Goroutine bool `json:"goroutine,omitempty"`
+}
+
+// ConfigError is an invalid value in a configuration
+type ConfigError struct {
+	// key of the invalid value, as in JSON or the environment
+	Key string
+	Err error
+}
+
+// Error returns a description that names the invalid key
+func (err *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s: %s", err.Key, err.Err)
+}
+
+// Unwrap returns the underlying error
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+// formats by name
+var formats = map[string]msg.Format{
+	"flat":    format.Flat,
+	"minimal": format.Minimal,
+	"json":    format.JSON,
+	"pretty":  format.Pretty,
+}
+
+// themes by name
+var themes = map[string]*msg.Theme{
+	"dark":          msg.Dark,
+	"light":         msg.Light,
+	"high-contrast": msg.HighContrast,
+}
+
+// level labels by name
+var labels = map[string]*msg.Labels{
+	"lowercase": msg.Lowercase,
+	"uppercase": msg.Uppercase,
+	"short":     msg.Short,
+	"letters":   msg.Letters,
+}
+
+// time formats by name
+var timeFormats = map[string]string{
+	"iso8601":    ISO8601,
+	"rfc3339":    time.RFC3339,
+	"unixmillis": format.UnixMillis,
+}
+
+// files opened as sinks by path, which are kept open for the lifetime of the
+// process and reused when a configuration is applied again
+var sinks = struct {
+	sync.Mutex
+	files map[string]*os.File
+}{
+	files: map[string]*os.File{},
+}
+
+// LoadConfig reads a configuration from a JSON file
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := decode(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// LoadEnv overrides the configuration with environment variables such as
+// CAPTAINSLOG_LEVEL, CAPTAINSLOG_FORMAT, or CAPTAINSLOG_LEVELS=app.db=trace,app.http=warn
+func (cfg *Config) LoadEnv() error {
+	strs := map[string]*string{
+		"NAME":        &cfg.Name,
+		"LEVEL":       &cfg.Level,
+		"THEME":       &cfg.Theme,
+		"LABELS":      &cfg.Labels,
+		"FORMAT":      &cfg.Format,
+		"TIME_FORMAT": &cfg.TimeFormat,
+		"STDOUT":      &cfg.Stdout,
+		"STDERR":      &cfg.Stderr,
+		"VMODULE":     &cfg.VModule,
+	}
+	for key, field := range strs {
+		if value, ok := os.LookupEnv(EnvPrefix + key); ok {
+			*field = value
+		}
+	}
+
+	if err := lookupBool("COLOR", func(color bool) { cfg.Color = &color }); err != nil {
+		return err
+	}
+	if err := lookupBool("GOROUTINE", func(goroutine bool) { cfg.Goroutine = goroutine }); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv(EnvPrefix + "NAME_CUTOFF"); ok {
+		cutoff, err := strconv.Atoi(value)
+		if err != nil {
+			return &ConfigError{EnvPrefix + "NAME_CUTOFF", fmt.Errorf("invalid integer %q", value)}
+		}
+		cfg.NameCutoff = cutoff
+	}
+	if value, ok := os.LookupEnv(EnvPrefix + "LABEL_WIDTH"); ok {
+		width, err := strconv.Atoi(value)
+		if err != nil {
+			return &ConfigError{EnvPrefix + "LABEL_WIDTH", fmt.Errorf("invalid integer %q", value)}
+		}
+		cfg.LabelWidth = &width
+	}
+	if value, ok := os.LookupEnv(EnvPrefix + "LEVELS"); ok {
+		cfg.Levels = map[string]string{}
+		for _, item := range strings.Split(value, ",") {
+			if len(strings.TrimSpace(item)) == 0 {
+				continue
+			}
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) != 2 {
+				return &ConfigError{EnvPrefix + "LEVELS", fmt.Errorf("invalid item %q", item)}
+			}
+			cfg.Levels[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return envError(cfg.Validate())
+}
+
+// envError renames the key of a validation error to the environment
+// variable that set the invalid value, if one did
+func envError(err error) error {
+	configErr := &ConfigError{}
+	if !errors.As(err, &configErr) {
+		return err
+	}
+
+	parts := strings.SplitN(configErr.Key, ".", 2)
+	var name strings.Builder
+	name.WriteString(EnvPrefix)
+	for _, r := range parts[0] {
+		if unicode.IsUpper(r) {
+			name.WriteByte('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+	if _, ok := os.LookupEnv(name.String()); !ok {
+		return err
+	}
+	if len(parts) > 1 {
+		return &ConfigError{name.String(), fmt.Errorf("logger %s: %w", parts[1], configErr.Err)}
+	}
+
+	return &ConfigError{name.String(), configErr.Err}
+}
+
+// lookupBool parses a boolean environment variable and passes it to set, if it exists
+func lookupBool(key string, set func(bool)) error {
+	value, ok := os.LookupEnv(EnvPrefix + key)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return &ConfigError{EnvPrefix + key, fmt.Errorf("invalid boolean %q", value)}
+	}
+	set(b)
+
+	return nil
+}
+
+// Validate returns an error naming the first invalid key of the configuration
+func (cfg Config) Validate() error {
+	if len(cfg.Level) > 0 {
+		if _, err := levels.Parse(cfg.Level); err != nil {
+			return &ConfigError{"level", err}
+		}
+	}
+	if _, ok := themes[strings.ToLower(cfg.Theme)]; !ok && len(cfg.Theme) > 0 {
+		return &ConfigError{"theme", fmt.Errorf("unknown theme %q", cfg.Theme)}
+	}
+	if _, ok := parseLabels(cfg.Labels); !ok && len(cfg.Labels) > 0 {
+		return &ConfigError{"labels", fmt.Errorf("unknown labels %q", cfg.Labels)}
+	}
+	if _, ok := formats[strings.ToLower(cfg.Format)]; !ok && len(cfg.Format) > 0 {
+		return &ConfigError{"format", fmt.Errorf("unknown format %q", cfg.Format)}
+	}
+	if cfg.NameCutoff < 0 {
+		return &ConfigError{"nameCutoff", fmt.Errorf("negative cutoff %d", cfg.NameCutoff)}
+	}
+	names := make([]string, 0, len(cfg.Levels))
+	for name := range cfg.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(name) == 0 {
+			return &ConfigError{"levels", fmt.Errorf("empty logger name")}
+		}
+		if _, err := levels.Parse(cfg.Levels[name]); err != nil {
+			return &ConfigError{fmt.Sprintf("levels.%s", name), err}
+		}
+	}
+	if _, err := vmodule.Parse(cfg.VModule); err != nil {
+		return &ConfigError{"vmodule", err}
+	}
+
+	return nil
+}
+
+// FromConfig returns a new logger with a configuration, and sets the levels of
+// the hierarchical loggers it names. Hierarchical loggers inherit the rest of
+// their options from the default logger, so pass the logger to SetDefault to
+// configure them as well.
+func FromConfig(cfg Config) (*Logger, error) {
+	log := NewLogger()
+	if err := cfg.apply(log); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// apply sets the options that a valid configuration controls on a logger,
+// using the defaults for the ones it leaves empty
+func (cfg Config) apply(log *Logger) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	defaults := NewLogger()
+	stdout, err := sink(cfg.Stdout, defaults.Stdout)
+	if err != nil {
+		return &ConfigError{"stdout", err}
+	}
+	stderr, err := sink(cfg.Stderr, defaults.Stderr)
+	if err != nil {
+		return &ConfigError{"stderr", err}
+	}
+
+	log.Name = cfg.Name
+	log.Stdout = stdout
+	log.Stderr = stderr
+	level := defaults.GetLevel()
+	if len(cfg.Level) > 0 {
+		level, _ = levels.Parse(cfg.Level)
+	}
+	log.SetLevel(level)
+	log.HasColor = defaults.HasColor
+	log.ForceColor = defaults.ForceColor
+	if cfg.Color != nil {
+		log.HasColor = *cfg.Color
+		log.ForceColor = *cfg.Color
+	}
+	log.Theme = themes[strings.ToLower(cfg.Theme)]
+	log.Labels = cfg.levelLabels()
+	log.Format = defaults.Format
+	if len(cfg.Format) > 0 {
+		log.Format = formats[strings.ToLower(cfg.Format)]
+	}
+	log.TimeFormat = defaults.TimeFormat
+	if len(cfg.TimeFormat) > 0 {
+		log.TimeFormat = cfg.TimeFormat
+		if layout, ok := timeFormats[strings.ToLower(cfg.TimeFormat)]; ok {
+			log.TimeFormat = layout
+		}
+	}
+	log.NameCutoff = defaults.NameCutoff
+	if cfg.NameCutoff > 0 {
+		log.NameCutoff = cfg.NameCutoff
+	}
+	log.Goroutine = cfg.Goroutine
+	log.VModule = nil
+	if len(cfg.VModule) > 0 {
+		log.VModule, _ = vmodule.Parse(cfg.VModule)
+	}
+
+	for name, text := range cfg.Levels {
+		level, _ := levels.Parse(text)
+		Get(name).SetLevel(level)
+	}
+
+	return nil
+}
+
+// levelLabels returns the level labels of the configuration, or nil for the defaults
+func (cfg Config) levelLabels() *msg.Labels {
+	named, _ := parseLabels(cfg.Labels)
+	if cfg.LabelWidth == nil {
+		return named
+	}
+	if named == nil {
+		named = msg.DefaultLabels
+	}
+	named = msg.Unpadded(named)
+	named.Width = *cfg.LabelWidth
+
+	return named
+}
+
+// parseLabels returns the level labels with a name, which may be wrapped in brackets
+func parseLabels(name string) (*msg.Labels, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	bracketed := strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]")
+	if bracketed {
+		name = name[1 : len(name)-1]
+	}
+	named, ok := labels[name]
+	if !ok || !bracketed {
+		return named, ok
+	}
+
+	return msg.Bracketed(named), true
+}
+
+// sink returns the stream that messages are written to
+func sink(target string, fallback *os.File) (*os.File, error) {
+	switch strings.ToLower(target) {
+	case "":
+		return fallback, nil
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	case "discard":
+		target = os.DevNull
+	}
+
+	sinks.Lock()
+	defer sinks.Unlock()
+
+	if file, ok := sinks.files[target]; ok {
+		return file, nil
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	sinks.files[target] = file
+
+	return file, nil
+}
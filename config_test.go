@@ -0,0 +1,186 @@
+package captainslog_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/preflight"
+)
+
+func ExampleFromConfig() {
+	cfg, err := captainslog.LoadConfig("logging.json")
+	if err == nil {
+		err = cfg.LoadEnv()
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	log, err := captainslog.FromConfig(cfg)
+	if err != nil {
+		panic(err)
+	}
+	captainslog.SetDefault(log)
+}
+
+func TestFromConfig(test *testing.T) {
+	t := preflight.Unit(test)
+
+	dir := t.T.TempDir()
+	path := filepath.Join(dir, "logging.json")
+	t.Expect(os.WriteFile(path, []byte(`{
+		"name": "enterprise",
+		"level": "info",
+		"color": false,
+		"theme": "light",
+		"labels": "[short]",
+		"labelWidth": 0,
+		"format": "JSON",
+		"timeFormat": "unixmillis",
+		"nameCutoff": 20,
+		"stdout": "stderr",
+		"stderr": "`+filepath.Join(dir, "errors.log")+`",
+		"levels": {"defiant.warp": "trace"},
+		"vmodule": "app/db=trace",
+		"goroutine": true
+	}`), 0o600)).Is().Nil()
+
+	cfg, err := captainslog.LoadConfig(path)
+	t.Expect(err).Is().Nil()
+
+	log, err := captainslog.FromConfig(cfg)
+	t.Expect(err).Is().Nil()
+	t.Expect(log.Name).Equals("enterprise")
+	t.Expect(log.GetLevel()).Equals(levels.Info)
+	t.Expect(log.HasColor).Equals(false)
+	t.Expect(log.Theme).Equals(msg.Light)
+	t.Expect(log.Labels.Label(levels.Info)).Equals("[INF]")
+	t.Expect(log.TimeFormat).Equals(format.UnixMillis)
+	t.Expect(log.NameCutoff).Equals(20)
+	t.Expect(log.Stdout).Equals(os.Stderr)
+	t.Expect(log.Stderr.Name()).Equals(filepath.Join(dir, "errors.log"))
+	t.Expect(log.VModule.String()).Equals("app/db=trace")
+	t.Expect(log.Goroutine).Equals(true)
+	t.Expect(captainslog.Get("defiant.warp").GetLevel()).Equals(levels.Trace)
+
+	// files should be reused
+	again, err := captainslog.FromConfig(cfg)
+	t.Expect(err).Is().Nil()
+	t.Expect(again.Stderr).Equals(log.Stderr)
+
+	log.Error("warp core breach")
+	written, err := os.ReadFile(filepath.Join(dir, "errors.log"))
+	t.Expect(err).Is().Nil()
+	t.Expect(string(written)).Matches(`"message":"warp core breach"`)
+}
+
+func TestDefaultConfig(test *testing.T) {
+	t := preflight.Unit(test)
+
+	log, err := captainslog.FromConfig(captainslog.Config{})
+	t.Expect(err).Is().Nil()
+	defaults := captainslog.NewLogger()
+	t.Expect(log.GetLevel()).Equals(defaults.GetLevel())
+	t.Expect(log.HasColor).Equals(defaults.HasColor)
+	t.Expect(log.TimeFormat).Equals(defaults.TimeFormat)
+	t.Expect(log.NameCutoff).Equals(defaults.NameCutoff)
+	t.Expect(log.Stdout).Equals(defaults.Stdout)
+	t.Expect(log.Stderr).Equals(defaults.Stderr)
+	t.Expect(log.VModule).Is().Nil()
+	t.Expect(log.Labels).Is().Nil()
+}
+
+func TestLoadEnv(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.T.Setenv("CAPTAINSLOG_LEVEL", "debug")
+	t.T.Setenv("CAPTAINSLOG_FORMAT", "minimal")
+	t.T.Setenv("CAPTAINSLOG_COLOR", "false")
+	t.T.Setenv("CAPTAINSLOG_NAME_CUTOFF", "12")
+	t.T.Setenv("CAPTAINSLOG_LABELS", "uppercase")
+	t.T.Setenv("CAPTAINSLOG_GOROUTINE", "true")
+	t.T.Setenv("CAPTAINSLOG_LABEL_WIDTH", "0")
+	t.T.Setenv("CAPTAINSLOG_LEVELS", "voyager.bridge=warn, voyager.sickbay=trace")
+
+	cfg := captainslog.Config{Level: "error", Format: "json", TimeFormat: "rfc3339"}
+	t.Expect(cfg.LoadEnv()).Is().Nil()
+
+	color := false
+	width := 0
+	t.Expect(cfg).Equals(captainslog.Config{
+		Level:      "debug",
+		Format:     "minimal",
+		Color:      &color,
+		Labels:     "uppercase",
+		LabelWidth: &width,
+		Goroutine:  true,
+		TimeFormat: "rfc3339",
+		NameCutoff: 12,
+		Levels: map[string]string{
+			"voyager.bridge":  "warn",
+			"voyager.sickbay": "trace",
+		},
+	})
+}
+
+func TestConfigErrors(test *testing.T) {
+	t := preflight.Unit(test)
+
+	// validation errors should name the invalid key
+	for key, cfg := range map[string]captainslog.Config{
+		"level":      {Level: "loud"},
+		"format":     {Format: "xml"},
+		"labels":     {Labels: "[loud"},
+		"nameCutoff": {NameCutoff: -1},
+		"levels.app": {Levels: map[string]string{"app": "loud"}},
+		"vmodule":    {VModule: "app/db"},
+		"stdout":     {Stdout: filepath.Join(t.T.TempDir(), "missing", "out.log")},
+		"levels":     {Levels: map[string]string{"": "info"}},
+	} {
+		_, err := captainslog.FromConfig(cfg)
+		configErr := &captainslog.ConfigError{}
+		t.Expect(errors.As(err, &configErr)).Equals(true)
+		t.Expect(configErr.Key).Equals(key)
+		t.Expect(err.Error()).Matches("^invalid config " + strings.ReplaceAll(key, ".", `\.`) + ": ")
+	}
+
+	t.T.Setenv("CAPTAINSLOG_NAME_CUTOFF", "many")
+	err := (&captainslog.Config{}).LoadEnv()
+	t.Expect(err).Is().Not().Nil()
+	t.Expect(err.Error()).Matches("CAPTAINSLOG_NAME_CUTOFF")
+	t.Expect(os.Unsetenv("CAPTAINSLOG_NAME_CUTOFF")).Is().Nil()
+
+	// invalid values in the environment should be named by their variables
+	for key, value := range map[string]string{
+		"CAPTAINSLOG_LEVEL":       "loud",
+		"CAPTAINSLOG_FORMAT":      "xml",
+		"CAPTAINSLOG_NAME_CUTOFF": "-1",
+		"CAPTAINSLOG_LEVELS":      "app=loud",
+	} {
+		t.T.Setenv(key, value)
+		err = (&captainslog.Config{}).LoadEnv()
+		configErr := &captainslog.ConfigError{}
+		t.Expect(errors.As(err, &configErr)).Equals(true)
+		t.Expect(configErr.Key).Equals(key)
+		t.Expect(err.Error()).Matches("^invalid config " + key + ": ")
+		t.Expect(os.Unsetenv(key)).Is().Nil()
+	}
+
+	// values from files should keep their keys
+	err = (&captainslog.Config{Format: "xml"}).LoadEnv()
+	t.Expect(err.Error()).Matches("^invalid config format: ")
+
+	// unknown keys in files should be named
+	path := filepath.Join(t.T.TempDir(), "logging.json")
+	t.Expect(os.WriteFile(path, []byte(`{"levle": "info"}`), 0o600)).Is().Nil()
+	_, err = captainslog.LoadConfig(path)
+	t.Expect(err).Is().Not().Nil()
+	t.Expect(err.Error()).Matches(`"levle"`)
+}
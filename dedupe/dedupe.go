@@ -0,0 +1,198 @@
+package dedupe
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// last is the most recent message that was printed
+type last struct {
+	signature string
+	level     int
+	name      string
+	first     time.Time
+	latest    time.Time
+	repeats   uint64
+	// properties used to print the report of repeated messages
+	message msg.Message
+	// prints the report when the window ends, if no different message arrives
+	timer *time.Timer
+}
+
+// Deduper is a filter that collapses consecutive identical messages, with
+// the same level, name, text, and fields, into a single report of how many
+// times the message was repeated. It is safe to share between loggers.
+type Deduper struct {
+	// maximum time between the first and the last repeated message
+	Window time.Duration
+	// clock that stamps the reports printed when the window ends or by Flush;
+	// set it to the clock of the logger so that they are in order with its messages
+	Clock clock.Clock
+
+	mutex sync.Mutex
+	last  *last
+}
+
+// New returns a filter that collapses identical messages within a window
+func New(window time.Duration) *Deduper {
+	return &Deduper{
+		Window: window,
+		Clock:  clock.System,
+	}
+}
+
+// Allow returns true if a message is not a repeat of the previous one.
+// The report of repeated messages is printed before the next message
+// that is different, or when the window ends.
+func (d *Deduper) Allow(message *msg.Message) bool {
+	signature := sign(message)
+
+	d.mutex.Lock()
+	if d.last != nil && d.last.signature == signature && message.Time.Sub(d.last.first) < d.Window {
+		d.last.repeats++
+		d.last.latest = message.Time
+		if d.last.timer == nil {
+			current := d.last
+			current.timer = time.AfterFunc(d.Window-message.Time.Sub(current.first), func() {
+				d.expire(current)
+			})
+		}
+		d.mutex.Unlock()
+
+		return false
+	}
+	report := d.report(d.latest())
+	d.last = &last{
+		signature: signature,
+		level:     message.Level,
+		name:      message.Name,
+		first:     message.Time,
+		latest:    message.Time,
+		message: msg.Message{
+			TimeFormat: message.TimeFormat,
+			Threshold:  message.Threshold,
+			HasColor:   message.HasColor,
+			ForceColor: message.ForceColor,
+			Theme:      message.Theme,
+			Labels:     message.Labels,
+			Stdout:     message.Stdout,
+			Stderr:     message.Stderr,
+			Print:      message.Print,
+		},
+	}
+	d.mutex.Unlock()
+
+	printReport(report)
+
+	return true
+}
+
+// Flush prints the report of repeated messages, if there is one,
+// stamped with the time of the clock
+func (d *Deduper) Flush() {
+	now := d.now()
+
+	d.mutex.Lock()
+	report := d.report(now)
+	d.last = nil
+	d.mutex.Unlock()
+
+	printReport(report)
+}
+
+// expire prints the report of a message whose window has ended,
+// unless a different message has already replaced it
+func (d *Deduper) expire(ended *last) {
+	now := d.now()
+
+	d.mutex.Lock()
+	var report *msg.Message
+	if d.last == ended {
+		report = d.report(now)
+		d.last = nil
+	}
+	d.mutex.Unlock()
+
+	printReport(report)
+}
+
+// now returns the current time according to the clock
+func (d *Deduper) now() time.Time {
+	if d.Clock != nil {
+		return d.Clock.Now()
+	}
+
+	return clock.System.Now()
+}
+
+// latest returns the time of the last repeated message, if there is one
+func (d *Deduper) latest() time.Time {
+	if d.last == nil {
+		return time.Time{}
+	}
+
+	return d.last.latest
+}
+
+// report returns a message reporting how many times the last message was repeated
+func (d *Deduper) report(now time.Time) *msg.Message {
+	if d.last == nil {
+		return nil
+	}
+	if d.last.timer != nil {
+		d.last.timer.Stop()
+		d.last.timer = nil
+	}
+	if d.last.repeats == 0 {
+		return nil
+	}
+
+	report := d.last.message
+	report.Time = now
+	report.Name = d.last.name
+	report.Level = d.last.level
+	report.Text = fmt.Sprintf("last message repeated %d times", d.last.repeats)
+	report.Template = "last message repeated %d times"
+	report.Data = []msg.Field{
+		msg.Int64("repeats", int64(d.last.repeats)),
+		msg.Duration("span", d.last.latest.Sub(d.last.first)),
+	}
+
+	return &report
+}
+
+// printReport prints a report, if there is one
+func printReport(report *msg.Message) {
+	if report != nil {
+		report.Print(report)
+	}
+}
+
+// sign returns a string that identifies a message by its level, name, text, and fields
+func sign(message *msg.Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\x00%s\x00%s", message.Level, message.Name, message.Text)
+	signFields(&b, message.Data)
+
+	return b.String()
+}
+
+// signFields writes the keys and values of fields to a signature
+func signFields(b *strings.Builder, fields []msg.Field) {
+	for _, field := range fields {
+		fmt.Fprintf(b, "\x00%d:%s=", field.Kind, field.Key)
+		if field.Kind == msg.GroupKind {
+			b.WriteString("{")
+			signFields(b, field.Group())
+			b.WriteString("}")
+
+			continue
+		}
+		fmt.Fprintf(b, "%#v", field.Value())
+	}
+}
@@ -0,0 +1,115 @@
+package dedupe_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/dedupe"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/preflight"
+)
+
+var stardate = time.Date(2364, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestDeduper(test *testing.T) {
+	t := preflight.Unit(test)
+
+	deduper := dedupe.New(time.Minute)
+
+	var reports []*msg.Message
+	send := func(text string, offset time.Duration, fields ...msg.Field) bool {
+		message := createMessage(levels.Error, text, stardate.Add(offset))
+		message.Data = fields
+		message.Print = func(report *msg.Message) {
+			reports = append(reports, report)
+		}
+
+		return deduper.Allow(message)
+	}
+
+	t.Expect(send("warp core breach", 0)).Equals(true)
+	t.Expect(send("warp core breach", time.Second)).Equals(false)
+	t.Expect(send("warp core breach", 3*time.Second)).Equals(false)
+	t.Expect(reports).HasLength(0)
+
+	// a different message should be preceded by a report
+	t.Expect(send("warp core breach", 4*time.Second, msg.Int("deck", 12))).Equals(true)
+	t.Expect(reports).HasLength(1)
+	t.Expect(reports[0].Text).Equals("last message repeated 2 times")
+	t.Expect(reports[0].Level).Equals(levels.Error)
+	t.Expect(reports[0].Data).Equals([]msg.Field{
+		msg.Int64("repeats", 2),
+		msg.Duration("span", 3*time.Second),
+	})
+
+	// messages outside the window should not be collapsed
+	t.Expect(send("warp core breach", 2*time.Minute, msg.Int("deck", 12))).Equals(true)
+	t.Expect(reports).HasLength(1)
+}
+
+func TestFlush(test *testing.T) {
+	t := preflight.Unit(test)
+
+	deduper := dedupe.New(time.Minute)
+
+	var reports []*msg.Message
+	for i := 0; i < 3; i++ {
+		message := createMessage(levels.Error, "warp core breach", stardate)
+		message.Print = func(report *msg.Message) {
+			reports = append(reports, report)
+		}
+		deduper.Allow(message)
+	}
+	deduper.Flush()
+	deduper.Flush()
+
+	t.Expect(reports).HasLength(1)
+	t.Expect(reports[0].Text).Equals("last message repeated 2 times")
+}
+
+func TestWindow(test *testing.T) {
+	t := preflight.Unit(test)
+
+	deduper := dedupe.New(20 * time.Millisecond)
+	deduper.Clock = clock.Fixed(stardate.Add(time.Hour))
+
+	reports := make(chan *msg.Message, 2)
+	for i := 0; i < 3; i++ {
+		message := createMessage(levels.Error, "warp core breach", stardate)
+		message.Print = func(report *msg.Message) {
+			reports <- report
+		}
+		deduper.Allow(message)
+	}
+
+	// repeats before a quiet period should be reported when the window ends
+	select {
+	case report := <-reports:
+		t.Expect(report.Text).Equals("last message repeated 2 times")
+		t.Expect(report.Time).Equals(stardate.Add(time.Hour))
+	case <-time.After(5 * time.Second):
+		t.T.Fatal("report was not printed")
+	}
+
+	deduper.Flush()
+	t.Expect(reports).HasLength(0)
+}
+
+/**
+ * Test Helpers
+ */
+func createMessage(level int, text string, now time.Time) *msg.Message {
+	return &msg.Message{
+		Time:      now,
+		Name:      "captainslog",
+		Text:      text,
+		Template:  text,
+		Level:     level,
+		Threshold: levels.Trace,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}
+}
@@ -0,0 +1,105 @@
+package captainslog
+
+import (
+	"sync/atomic"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// the process-wide default logger
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(NewLogger())
+}
+
+// Default returns the default logger used by the package-level functions
+func Default() *Logger {
+	return defaultLogger.Load().(*Logger)
+}
+
+// SetDefault replaces the default logger, so that the package-level
+// functions log through it from then on
+func SetDefault(log *Logger) {
+	if log == nil {
+		log = NewLogger()
+	}
+	defaultLogger.Store(log)
+}
+
+// With returns a copy of the default logger that adds fields to every message
+func With(fields ...msg.Field) *Logger {
+	return Default().With(fields...)
+}
+
+// WithGroup returns a copy of the default logger that nests the fields
+// of every message under a name
+func WithGroup(name string) *Logger {
+	return Default().WithGroup(name)
+}
+
+// I returns a single field that can be added to logs
+func I(name string, value interface{}) msg.Field {
+	return msg.Any(name, value)
+}
+
+// Field starts a message on the default logger with a data field
+func Field(name string, value interface{}) *msg.Message {
+	return Default().message().Field(name, value)
+}
+
+// Fields starts a message on the default logger with multiple data fields
+func Fields(fields ...msg.Field) *msg.Message {
+	return Default().message().Fields(fields...)
+}
+
+// Enabled returns true if the default logger logs messages with the given level
+func Enabled(level int) bool {
+	return Default().enabled(level)
+}
+
+// LogFunc logs a message on the default logger with the given level, calling
+// a function to produce the text only if the message is logged
+func LogFunc(level int, text func() string) {
+	Default().message().LogFunc(level, text)
+}
+
+// Trace logs a message on the default logger with level Trace
+func Trace(format string, args ...interface{}) {
+	Default().message().Trace(format, args...)
+}
+
+// Debug logs a message on the default logger with level Debug
+func Debug(format string, args ...interface{}) {
+	Default().message().Debug(format, args...)
+}
+
+// Info logs a message on the default logger with level Info
+func Info(format string, args ...interface{}) {
+	Default().message().Info(format, args...)
+}
+
+// Warn logs a message on the default logger with level Warn
+func Warn(format string, args ...interface{}) {
+	Default().message().Warn(format, args...)
+}
+
+// Error logs a message on the default logger with level Error
+func Error(format string, args ...interface{}) {
+	Default().message().Error(format, args...)
+}
+
+// Exit logs an error on the default logger and exits with the given code
+func Exit(code int, format string, args ...interface{}) {
+	Default().message().Exit(code, format, args...)
+}
+
+// Fatal logs an error on the default logger and exits with code 1
+func Fatal(format string, args ...interface{}) {
+	Default().message().Fatal(format, args...)
+}
+
+// Panic logs an error on the default logger and panics
+func Panic(format string, args ...interface{}) {
+	Default().message().Panic(format, args...)
+}
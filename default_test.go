@@ -0,0 +1,128 @@
+package captainslog_test
+
+import (
+	"os"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/preflight"
+	"vincent.click/pkg/captainslog/v2/vmodule"
+)
+
+func ExampleSetDefault() {
+	log := captainslog.NewLogger()
+	log.SetLevel(levels.Info)
+	captainslog.SetDefault(log)
+
+	captainslog.Field("captain", "picard").Info("starship enterprise")
+}
+
+func TestDefault(test *testing.T) {
+	t := preflight.Unit(test)
+
+	// should return the same logger every time
+	t.Expect(captainslog.Default()).Is().Not().Nil()
+	t.Expect(captainslog.Default()).Equals(captainslog.Default())
+
+	// should use default values
+	t.Expect(captainslog.Default().GetLevel()).Equals(levels.Debug)
+	t.Expect(captainslog.Enabled(levels.Trace)).Equals(false)
+}
+
+func TestDefaultSetLevel(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := captainslog.NewLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		captainslog.SetDefault(log)
+		child := captainslog.With(msg.String("ship", "enterprise"))
+
+		// changing the level of the default logger should be safe while logging
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				captainslog.Trace("scan %d", i)
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			captainslog.Default().SetLevel(i % levels.Warn)
+		}
+		<-done
+
+		captainslog.Default().SetLevel(levels.Info)
+		child.Debug("hidden")
+		child.Info("engage")
+	})
+
+	stdout[len(stdout)-1].Message.Equals("engage")
+}
+
+func TestDefaultVModule(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+
+	spec, err := vmodule.Parse("default_test=trace")
+	t.Expect(err).Is().Nil()
+	log := captainslog.NewLogger()
+	log.SetLevel(levels.Info)
+	log.VModule = spec
+	captainslog.SetDefault(log)
+
+	// the call site should be the caller of the package-level function
+	t.Expect(captainslog.Enabled(levels.Trace)).Equals(true)
+	t.Expect(captainslog.Default().Enabled(levels.Trace)).Equals(true)
+	t.Expect(spec.Set("log_test=trace")).Is().Nil()
+	t.Expect(captainslog.Enabled(levels.Trace)).Equals(false)
+}
+
+func TestSetDefault(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+
+	stdout, stderr := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		captainslog.SetDefault(log)
+
+		captainslog.Trace("message %d", 1)
+		captainslog.Info("message %d", 2)
+		captainslog.Field("captain", "picard").Debug("message %d", 3)
+		captainslog.Fields(msg.Int("deck", 10)).Warn("message %d", 4)
+		captainslog.With(msg.String("ship", "enterprise")).Error("message %d", 5)
+		captainslog.LogFunc(levels.Info, func() string {
+			return "message 6"
+		})
+	})
+
+	t.Expect(stdout).HasLength(4)
+	t.Expect(stderr).HasLength(2)
+
+	stdout[0].Message.Equals("message 1")
+	stdout[1].Message.Equals("message 2")
+	stdout[2].Fields.Equals(`captain="picard"`)
+	stdout[3].Message.Equals("message 6")
+	stderr[0].Fields.Equals("deck=10")
+	stderr[1].Fields.Equals(`ship="enterprise"`)
+
+	// should use the name of the caller
+	stdout[0].Name.Matches("func[0-9]+")
+	stderr[0].Name.Matches("func[0-9]+")
+
+	// should restore a logger with default values
+	captainslog.SetDefault(nil)
+	t.Expect(captainslog.Default()).Is().Not().Nil()
+	t.Expect(captainslog.Default().GetLevel()).Equals(levels.Debug)
+}
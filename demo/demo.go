@@ -10,7 +10,7 @@ var log *captainslog.Logger
 func init() {
 	log = captainslog.NewLogger()
 	log.Name = "captainslog"
-	log.Level = levels.Trace
+	log.SetLevel(levels.Trace)
 }
 
 func main() {
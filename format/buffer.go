@@ -0,0 +1,76 @@
+package format
+
+import (
+	"os"
+	"sync"
+	"unicode/utf8"
+)
+
+// Buffers larger than this are not returned to the pool
+const maxBufferSize = 64 << 10
+
+// buffer is a reusable byte slice for building a log line
+type buffer []byte
+
+// bufferPool is a synchronized pool of buffers
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make(buffer, 0, 1024)
+
+		return &b
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *buffer {
+	b := bufferPool.Get().(*buffer)
+	*b = (*b)[:0]
+
+	return b
+}
+
+// flush writes the contents of the buffer to a stream and returns it to the pool
+func (b *buffer) flush(stream *os.File) {
+	_, _ = stream.Write(*b)
+	if cap(*b) <= maxBufferSize {
+		bufferPool.Put(b)
+	}
+}
+
+// appendJSONString appends a string as a quoted and escaped JSON string
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, `�`...)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+
+	return append(b, '"')
+}
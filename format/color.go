@@ -0,0 +1,34 @@
+package format
+
+import (
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// plain is the theme of messages without colors
+var plain = &msg.Theme{}
+
+// themeOf returns the theme of a message if it is colored, or a theme without styles
+func themeOf(message *msg.Message) *msg.Theme {
+	if message.Colored() {
+		return message.Colors()
+	}
+
+	return plain
+}
+
+// appendLabel appends the padded label of the message level with a style
+func appendLabel(b []byte, style msg.Style, message *msg.Message) []byte {
+	b = append(b, style...)
+	b = message.LevelLabels().Append(b, message.Level)
+
+	return appendReset(b, style)
+}
+
+// appendReset appends the sequence that ends a style, if there is one
+func appendReset(b []byte, style msg.Style) []byte {
+	if len(style) > 0 {
+		return append(b, msg.Reset...)
+	}
+
+	return b
+}
@@ -0,0 +1,74 @@
+package format
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/values"
+)
+
+// appendText appends the value of a field as text
+func appendText(b []byte, field msg.Field) []byte {
+	switch field.Kind {
+	case msg.StringKind:
+		return strconv.AppendQuote(b, field.Str)
+	case msg.IntKind:
+		return strconv.AppendInt(b, field.Num, 10)
+	case msg.FloatKind:
+		return strconv.AppendFloat(b, field.Float(), 'g', -1, 64)
+	case msg.BoolKind:
+		return strconv.AppendBool(b, field.Bool())
+	case msg.DurationKind:
+		return strconv.AppendQuote(b, field.Duration().String())
+	case msg.TimeKind:
+		b = append(b, '"')
+		b = field.Time().AppendFormat(b, time.RFC3339Nano)
+
+		return append(b, '"')
+	case msg.ErrorKind:
+		if err := field.Err(); err != nil {
+			return strconv.AppendQuote(b, values.ErrorText(err))
+		}
+
+		return append(b, "nil"...)
+	default:
+		return appendGoValue(b, reflect.ValueOf(field.Any), 0)
+	}
+}
+
+// appendJSON appends the value of a field as JSON
+func appendJSON(b []byte, field msg.Field) []byte {
+	switch field.Kind {
+	case msg.StringKind:
+		return appendJSONString(b, field.Str)
+	case msg.IntKind:
+		return strconv.AppendInt(b, field.Num, 10)
+	case msg.FloatKind:
+		f := field.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return appendJSONString(b, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+
+		return strconv.AppendFloat(b, f, 'g', -1, 64)
+	case msg.BoolKind:
+		return strconv.AppendBool(b, field.Bool())
+	case msg.DurationKind:
+		return appendJSONString(b, field.Duration().String())
+	case msg.TimeKind:
+		b = append(b, '"')
+		b = field.Time().AppendFormat(b, time.RFC3339Nano)
+
+		return append(b, '"')
+	case msg.ErrorKind:
+		if err := field.Err(); err != nil {
+			return appendJSONString(b, values.ErrorText(err))
+		}
+
+		return append(b, "null"...)
+	default:
+		return appendJSONValue(b, reflect.ValueOf(field.Any), 0)
+	}
+}
@@ -0,0 +1,113 @@
+package format
+
+import (
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// hasMembers returns true if a list of fields has any values, including nested ones
+func hasMembers(fields []msg.Field) bool {
+	for i, field := range fields {
+		switch field.Kind {
+		case msg.GroupKind:
+			if hasMembers(field.Group()) {
+				return true
+			}
+		case msg.OpenGroupKind:
+			return hasMembers(fields[i+1:])
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendTextFields appends fields as key=value pairs separated by commas,
+// with the names of groups prepended to their keys, and returns the
+// total number of pairs written so far
+func appendTextFields(b []byte, fields []msg.Field, prefix string, n int, theme *msg.Theme) ([]byte, int) {
+	for i, field := range fields {
+		switch field.Kind {
+		case msg.GroupKind:
+			b, n = appendTextFields(b, field.Group(), prefix+field.Key+".", n, theme)
+		case msg.OpenGroupKind:
+			return appendTextFields(b, fields[i+1:], prefix+field.Key+".", n, theme)
+		default:
+			if n > 0 {
+				b = append(b, ", "...)
+			}
+			b = append(b, theme.Key...)
+			b = append(b, prefix...)
+			b = append(b, field.Key...)
+			b = appendReset(b, theme.Key)
+			b = append(b, '=')
+			b = append(b, theme.Value...)
+			b = appendText(b, field)
+			b = appendReset(b, theme.Value)
+			n++
+		}
+	}
+
+	return b, n
+}
+
+// appendJSONFields appends fields as the members of a JSON object,
+// with groups nested in objects of their own
+func appendJSONFields(b []byte, fields []msg.Field) []byte {
+	n := 0
+	for i, field := range fields {
+		switch field.Kind {
+		case msg.GroupKind, msg.OpenGroupKind:
+			nested := field.Group()
+			if field.Kind == msg.OpenGroupKind {
+				nested = fields[i+1:]
+			}
+			if hasMembers(nested) {
+				if n > 0 {
+					b = append(b, ',')
+				}
+				b = appendJSONString(b, field.Key)
+				b = append(b, ":{"...)
+				if static, ok := field.Any.(*msg.Static); ok {
+					b = append(b, static.Encoding("json", appendJSONFields)...)
+				} else {
+					b = appendJSONFields(b, nested)
+				}
+				b = append(b, '}')
+				n++
+			}
+			if field.Kind == msg.OpenGroupKind {
+				return b
+			}
+		default:
+			if n > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, field.Key)
+			b = append(b, ':')
+			b = appendJSON(b, field)
+			n++
+		}
+	}
+
+	return b
+}
+
+// flatten returns a list of fields without groups, with the names
+// of groups prepended to their keys
+func flatten(fields []msg.Field, prefix string) []msg.Field {
+	flat := make([]msg.Field, 0, len(fields))
+	for i, field := range fields {
+		switch field.Kind {
+		case msg.GroupKind:
+			flat = append(flat, flatten(field.Group(), prefix+field.Key+".")...)
+		case msg.OpenGroupKind:
+			return append(flat, flatten(fields[i+1:], prefix+field.Key+".")...)
+		default:
+			field.Key = prefix + field.Key
+			flat = append(flat, field)
+		}
+	}
+
+	return flat
+}
@@ -1,39 +1,29 @@
 package format
 
 import (
-	"fmt"
-	"os"
-
 	"vincent.click/pkg/captainslog/v2/msg"
 )
 
 // Flat formats a message as flat text
 func Flat(msg *msg.Message) {
-	stream, level, colorize := msg.Props()
-	if !msg.HasColor {
-		colorize = fmt.Sprintf
-	}
+	stream, _, _ := msg.Props()
+	theme := themeOf(msg)
+	style := theme.Level(msg.Level)
 
-	Write(stream, colorize("%6s", level))
-	separate(stream)
-	Write(stream, msg.Time)
-	separate(stream)
-	Write(stream, colorize(msg.Name))
-	if len(msg.Data) > 0 {
-		separate(stream)
-		for i := 0; i < len(msg.Data)-1; i += 2 {
-			if i > 0 {
-				Write(stream, ", ")
-			}
-			Write(stream, fmt.Sprintf("%s=%#v", msg.Data[i], msg.Data[i+1]))
-		}
+	b := getBuffer()
+	*b = appendLabel(*b, style, msg)
+	*b = theme.Separator.Append(*b, separator)
+	*b = append(*b, theme.Time...)
+	*b = appendTime(*b, msg.Time, msg.TimeFormat)
+	*b = appendReset(*b, theme.Time)
+	*b = theme.Separator.Append(*b, separator)
+	*b = style.Append(*b, msg.Name)
+	if hasMembers(msg.Data) {
+		*b = theme.Separator.Append(*b, separator)
+		*b, _ = appendTextFields(*b, msg.Data, "", 0, theme)
 	}
-	separate(stream)
-	Write(stream, msg.Text)
-	Write(stream, "\n")
-}
-
-// separate prints out a separator between parts of the message
-func separate(stream *os.File) {
-	Write(stream, " :: ")
+	*b = theme.Separator.Append(*b, separator)
+	*b = append(*b, msg.Text...)
+	*b = append(*b, '\n')
+	b.flush(stream)
 }
@@ -1,8 +1,10 @@
 package format_test
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"vincent.click/pkg/captainslog/v2/format"
 	"vincent.click/pkg/captainslog/v2/levels"
@@ -16,18 +18,17 @@ func TestFlat(test *testing.T) {
 	w := t.ExpectWritten(func(stdout *os.File) {
 
 		message := &msg.Message{
-			Time:      "08-28-2019 12:32:24 PST",
-			Name:      "captainslog",
-			Text:      "starship enterprise",
-			Level:     levels.Info,
-			Threshold: levels.Info,
-			Stdout:    stdout,
-			Print:     format.Flat,
-			Data: []interface{}{
-				"captain",
-				"picard",
-				"first officer",
-				"riker",
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Flat,
+			Data: []msg.Field{
+				msg.String("captain", "picard"),
+				msg.String("first officer", "riker"),
 			},
 		}
 
@@ -38,3 +39,161 @@ func TestFlat(test *testing.T) {
 
 	w.Text().Equals("  info :: 08-28-2019 12:32:24 PST :: captainslog :: captain=\"picard\", first officer=\"riker\" :: starship enterprise\n")
 }
+
+func TestFlatValues(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "red alert",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Flat,
+			Data: []msg.Field{
+				msg.Duration("eta", 90*time.Second),
+				msg.Err("error", errors.New("shields down")),
+				msg.Any("crew", map[string]int{"decks": 42}),
+				msg.Any("watches", []time.Duration{4 * time.Hour, 8 * time.Hour}),
+				msg.Any("watch", watch{"data", 4 * time.Hour, nil}),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	// nested values should be encoded as well
+	w.Text().Equals("  info :: 08-28-2019 12:32:24 PST :: captainslog :: eta=\"1m30s\", error=\"shields down\", " +
+		"crew=map[string]int{\"decks\":42}, watches=[]time.Duration{\"4h0m0s\", \"8h0m0s\"}, " +
+		"watch=format_test.watch{Officer:\"data\", Length:\"4h0m0s\", Relief:nil} :: red alert\n")
+}
+
+func TestFlatGroups(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "request",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Flat,
+			Data: []msg.Field{
+				msg.String("ship", "enterprise"),
+				msg.OpenGroup("bridge"),
+				msg.Group("http", msg.String("method", "GET"), msg.Int("status", 200)),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("  info :: 08-28-2019 12:32:24 PST :: captainslog :: ship=\"enterprise\", bridge.http.method=\"GET\", bridge.http.status=200 :: request\n")
+}
+
+func TestFlatUnixMillis(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: format.UnixMillis,
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Flat,
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("  info :: 1567024344000 :: captainslog :: starship enterprise\n")
+}
+
+func TestFlatColors(test *testing.T) {
+	t := preflight.Unit(test)
+	t.T.Setenv("NO_COLOR", "1")
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			HasColor:   true,
+			ForceColor: true,
+			Theme:      &msg.Theme{Info: msg.ANSI(94)},
+			Stdout:     stdout,
+			Print:      format.Flat,
+		}
+
+		message.Print(message)
+
+		// colors are not used on streams that are not terminals unless forced
+		message.ForceColor = false
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("\x1b[94m  info\x1b[0m :: 08-28-2019 12:32:24 PST :: \x1b[94mcaptainslog\x1b[0m :: starship enterprise\n" +
+		"  info :: 08-28-2019 12:32:24 PST :: captainslog :: starship enterprise\n")
+}
+
+func TestFlatTheme(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "15:04",
+			Name:       "captainslog",
+			Text:       "red alert",
+			Level:      levels.Warn,
+			Threshold:  levels.Info,
+			HasColor:   true,
+			ForceColor: true,
+			Theme: &msg.Theme{
+				Warn:      msg.RGB(255, 128, 0),
+				Time:      msg.Color256(244),
+				Key:       msg.ANSI(1),
+				Value:     msg.ANSI(32),
+				Separator: msg.ANSI(90),
+			},
+			Stderr: stdout,
+			Print:  format.Flat,
+			Data: []msg.Field{
+				msg.Group("shields", msg.Int("power", 20)),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("\x1b[38;2;255;128;0m  warn\x1b[0m\x1b[90m :: \x1b[0m\x1b[38;5;244m12:32\x1b[0m\x1b[90m :: \x1b[0m" +
+		"\x1b[38;2;255;128;0mcaptainslog\x1b[0m\x1b[90m :: \x1b[0m\x1b[1mshields.power\x1b[0m=\x1b[32m20\x1b[0m" +
+		"\x1b[90m :: \x1b[0mred alert\n")
+}
@@ -0,0 +1,15 @@
+package format_test
+
+import (
+	"time"
+)
+
+// time of the test messages
+var stardate = time.Date(2019, 8, 28, 12, 32, 24, 0, time.FixedZone("PST", -8*60*60))
+
+// value with nested fields that have encoders
+type watch struct {
+	Officer string        `json:"officer"`
+	Length  time.Duration `json:"length"`
+	Relief  *officer      `json:"relief,omitempty"`
+}
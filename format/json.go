@@ -1,7 +1,7 @@
 package format
 
 import (
-	"fmt"
+	"time"
 
 	"vincent.click/pkg/captainslog/v2/msg"
 )
@@ -10,16 +10,22 @@ import (
 func JSON(msg *msg.Message) {
 	stream, level, _ := msg.Props()
 
-	Write(stream, fmt.Sprintf(`{"level":"%s","time":"%s","from":"%s",`, level, msg.Time, msg.Name))
-	if len(msg.Data) > 0 {
-		Write(stream, `"fields":{`)
-		for i := 0; i < len(msg.Data)-1; i += 2 {
-			if i > 0 {
-				Write(stream, ",")
-			}
-			Write(stream, fmt.Sprintf(`"%s":%#v`, msg.Data[i], msg.Data[i+1]))
-		}
-		Write(stream, "},")
+	b := getBuffer()
+	*b = append(*b, `{"level":`...)
+	*b = appendJSONString(*b, level)
+	*b = append(*b, `,"time":"`...)
+	*b = msg.Time.AppendFormat(*b, time.RFC3339Nano)
+	*b = append(*b, '"')
+	*b = append(*b, `,"from":`...)
+	*b = appendJSONString(*b, msg.Name)
+	*b = append(*b, ',')
+	if hasMembers(msg.Data) {
+		*b = append(*b, `"fields":{`...)
+		*b = appendJSONFields(*b, msg.Data)
+		*b = append(*b, "},"...)
 	}
-	Write(stream, fmt.Sprintf("\"message\":\"%s\"}\n", msg.Text))
+	*b = append(*b, `"message":`...)
+	*b = appendJSONString(*b, msg.Text)
+	*b = append(*b, "}\n"...)
+	b.flush(stream)
 }
@@ -3,6 +3,7 @@ package format_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"vincent.click/pkg/captainslog/v2/format"
 	"vincent.click/pkg/captainslog/v2/levels"
@@ -16,18 +17,17 @@ func TestJSON(test *testing.T) {
 	w := t.ExpectWritten(func(stdout *os.File) {
 
 		message := &msg.Message{
-			Time:      "08-28-2019 12:32:24 PST",
-			Name:      "captainslog",
-			Text:      "starship enterprise",
-			Level:     levels.Info,
-			Threshold: levels.Info,
-			Stdout:    stdout,
-			Print:     format.JSON,
-			Data: []interface{}{
-				"captain",
-				"picard",
-				"first officer",
-				"riker",
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.JSON,
+			Data: []msg.Field{
+				msg.String("captain", "picard"),
+				msg.String("first officer", "riker"),
 			},
 		}
 
@@ -36,5 +36,100 @@ func TestJSON(test *testing.T) {
 	})
 	defer w.Close()
 
-	w.Text().Equals("{\"level\":\"info\",\"time\":\"08-28-2019 12:32:24 PST\",\"from\":\"captainslog\",\"fields\":{\"captain\":\"picard\",\"first officer\":\"riker\"},\"message\":\"starship enterprise\"}\n")
+	w.Text().Equals("{\"level\":\"info\",\"time\":\"2019-08-28T12:32:24-08:00\",\"from\":\"captainslog\",\"fields\":{\"captain\":\"picard\",\"first officer\":\"riker\"},\"message\":\"starship enterprise\"}\n")
+}
+
+func TestJSONValues(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "red alert",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.JSON,
+			Data: []msg.Field{
+				msg.Duration("eta", 90*time.Second),
+				msg.Any("crew", map[string]int{"decks": 42}),
+				msg.Float64("warp", 9.5),
+				msg.Bool("cloaked", false),
+				msg.Any("watches", []time.Duration{4 * time.Hour, 8 * time.Hour}),
+				msg.Any("watch", watch{"data", 4 * time.Hour, &officer{"riker", "commander"}}),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	// nested values should be encoded as well
+	w.Text().Equals("{\"level\":\"info\",\"time\":\"2019-08-28T12:32:24-08:00\",\"from\":\"captainslog\",\"fields\":{" +
+		"\"eta\":\"1m30s\",\"crew\":{\"decks\":42},\"warp\":9.5,\"cloaked\":false,\"watches\":[\"4h0m0s\",\"8h0m0s\"]," +
+		"\"watch\":{\"officer\":\"data\",\"length\":\"4h0m0s\",\"relief\":{\"Name\":\"riker\",\"Rank\":\"commander\"}}" +
+		"},\"message\":\"red alert\"}\n")
+}
+
+func TestJSONGroups(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "request",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.JSON,
+			Data: []msg.Field{
+				msg.String("ship", "enterprise"),
+				msg.OpenGroup("bridge"),
+				msg.OpenGroup("empty"),
+				msg.Group("http", msg.String("method", "GET"), msg.Int("status", 200)),
+				msg.Group("none"),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("{\"level\":\"info\",\"time\":\"2019-08-28T12:32:24-08:00\",\"from\":\"captainslog\",\"fields\":{\"ship\":\"enterprise\",\"bridge\":{\"empty\":{\"http\":{\"method\":\"GET\",\"status\":200}}}},\"message\":\"request\"}\n")
+}
+
+func TestJSONStatic(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		process := msg.StaticGroup("process", msg.String("service", "warp"), msg.Int("pid", 1701))
+		for i := 0; i < 2; i++ {
+			message := &msg.Message{
+				Time:      stardate,
+				Name:      "captainslog",
+				Text:      "engage",
+				Level:     levels.Info,
+				Threshold: levels.Info,
+				Stdout:    stdout,
+				Print:     format.JSON,
+				Data:      []msg.Field{process, msg.Int("warp", i)},
+			}
+
+			message.Print(message)
+		}
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("{\"level\":\"info\",\"time\":\"2019-08-28T12:32:24-08:00\",\"from\":\"captainslog\",\"fields\":{\"process\":{\"service\":\"warp\",\"pid\":1701},\"warp\":0},\"message\":\"engage\"}\n" +
+		"{\"level\":\"info\",\"time\":\"2019-08-28T12:32:24-08:00\",\"from\":\"captainslog\",\"fields\":{\"process\":{\"service\":\"warp\",\"pid\":1701},\"warp\":1},\"message\":\"engage\"}\n")
 }
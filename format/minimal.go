@@ -1,30 +1,23 @@
 package format
 
 import (
-	"fmt"
-
 	"vincent.click/pkg/captainslog/v2/msg"
 )
 
 // Minimal prints a minimal log with no timestamp or name
 func Minimal(msg *msg.Message) {
-	stream, level, colorize := msg.Props()
-	if !msg.HasColor {
-		colorize = fmt.Sprintf
-	}
+	stream, _, _ := msg.Props()
+	theme := themeOf(msg)
 
-	Write(stream, colorize("%6s", level))
-	Write(stream, ": ")
-	if len(msg.Data) > 0 {
-		Write(stream, "[")
-		for i := 0; i < len(msg.Data)-1; i += 2 {
-			if i > 0 {
-				Write(stream, ", ")
-			}
-			Write(stream, fmt.Sprintf("%s=%#v", msg.Data[i], msg.Data[i+1]))
-		}
-		Write(stream, "] ")
+	b := getBuffer()
+	*b = appendLabel(*b, theme.Level(msg.Level), msg)
+	*b = append(*b, ": "...)
+	if hasMembers(msg.Data) {
+		*b = append(*b, '[')
+		*b, _ = appendTextFields(*b, msg.Data, "", 0, theme)
+		*b = append(*b, "] "...)
 	}
-	Write(stream, msg.Text)
-	Write(stream, "\n")
+	*b = append(*b, msg.Text...)
+	*b = append(*b, '\n')
+	b.flush(stream)
 }
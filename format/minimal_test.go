@@ -16,18 +16,17 @@ func TestMinimal(test *testing.T) {
 	w := t.ExpectWritten(func(stdout *os.File) {
 
 		message := &msg.Message{
-			Time:      "08-28-2019 12:32:24 PST",
-			Name:      "captainslog",
-			Text:      "starship enterprise",
-			Level:     levels.Info,
-			Threshold: levels.Info,
-			Stdout:    stdout,
-			Print:     format.Minimal,
-			Data: []interface{}{
-				"captain",
-				"picard",
-				"first officer",
-				"riker",
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Minimal,
+			Data: []msg.Field{
+				msg.String("captain", "picard"),
+				msg.String("first officer", "riker"),
 			},
 		}
 
@@ -38,3 +37,30 @@ func TestMinimal(test *testing.T) {
 
 	w.Text().Equals("  info: [captain=\"picard\", first officer=\"riker\"] starship enterprise\n")
 }
+
+func TestMinimalLabels(test *testing.T) {
+	t := preflight.Unit(test)
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		labels := msg.Unpadded(msg.Bracketed(msg.Uppercase))
+		labels.Custom = map[int]string{7: "AUDIT"}
+
+		for _, level := range []int{levels.Warn, 7} {
+			message := &msg.Message{
+				Text:      "shields up",
+				Level:     level,
+				Threshold: levels.Info,
+				Labels:    labels,
+				Stderr:    stdout,
+				Print:     format.Minimal,
+			}
+
+			message.Print(message)
+		}
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("[WARN]: shields up\n[AUDIT]: shields up\n")
+}
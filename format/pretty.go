@@ -0,0 +1,280 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/values"
+)
+
+// Layout of pretty logs
+const (
+	// maximum depth of nested values
+	prettyDepth = 8
+	// minimum number of columns to wrap text into
+	prettyMinWidth = 20
+	// indentation of nested values
+	prettyIndent = "    "
+	// indentation of fields and continued lines
+	prettyMargin = "          "
+)
+
+// Styles of values by type, if the theme does not set a style for values
+var (
+	stringStyle = msg.ANSI(92)
+	numberStyle = msg.ANSI(96)
+	boolStyle   = msg.ANSI(93)
+	nilStyle    = msg.ANSI(90)
+)
+
+// type of values that are already encoded
+var rawType = reflect.TypeOf(values.Raw(""))
+
+// palette colorizes parts of a pretty log
+type palette struct {
+	key     func(...interface{}) string
+	str     func(...interface{}) string
+	number  func(...interface{}) string
+	boolean func(...interface{}) string
+	null    func(...interface{}) string
+}
+
+// Pretty formats a message as human-friendly text, with each field on its own line
+func Pretty(msg *msg.Message) {
+	stream, _, _ := msg.Props()
+	theme := themeOf(msg)
+	style := theme.Level(msg.Level)
+	p := newPalette(theme)
+
+	b := getBuffer()
+	label := msg.Label()
+	*b = style.Append(*b, label)
+	*b = theme.Separator.Append(*b, separator)
+	*b = append(*b, theme.Time...)
+	start := len(*b)
+	*b = appendTime(*b, msg.Time, msg.TimeFormat)
+	// column where the text starts, not counting styles
+	column := utf8.RuneCountInString(label) + utf8.RuneCount((*b)[start:]) +
+		utf8.RuneCountInString(msg.Name) + 3*len(separator)
+	*b = appendReset(*b, theme.Time)
+	*b = theme.Separator.Append(*b, separator)
+	*b = style.Append(*b, msg.Name)
+	*b = theme.Separator.Append(*b, separator)
+	*b = append(*b, wrap(msg.Text, column, streamWidth(stream))...)
+	*b = append(*b, '\n')
+
+	fields := flatten(msg.Data, "")
+	keyWidth := 0
+	for _, field := range fields {
+		if n := utf8.RuneCountInString(field.Key); n > keyWidth {
+			keyWidth = n
+		}
+	}
+	indent := prettyMargin + strings.Repeat(" ", keyWidth+3)
+	for _, field := range fields {
+		*b = append(*b, prettyMargin...)
+		*b = append(*b, p.key(field.Key)...)
+		*b = append(*b, strings.Repeat(" ", keyWidth-utf8.RuneCountInString(field.Key))...)
+		*b = append(*b, " = "...)
+		*b = append(*b, p.value(reflect.ValueOf(field.Value()), indent, 0)...)
+		*b = append(*b, '\n')
+	}
+	b.flush(stream)
+}
+
+// newPalette returns a palette with the styles of a theme
+func newPalette(theme *msg.Theme) palette {
+	if len(theme.Value) > 0 || theme == plain {
+		value := theme.Value.Sprint
+
+		return palette{theme.Key.Sprint, value, value, value, value}
+	}
+
+	return palette{theme.Key.Sprint, stringStyle.Sprint, numberStyle.Sprint, boolStyle.Sprint, nilStyle.Sprint}
+}
+
+// wrap breaks text into indented lines that fit within the given width,
+// starting at the given column
+func wrap(text string, column int, width int) string {
+	if width-len(prettyMargin) < prettyMinWidth {
+		width = int(^uint(0) >> 1)
+	}
+
+	var b strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteString("\n")
+			b.WriteString(prettyMargin)
+			column = len(prettyMargin)
+		}
+		for j, word := range strings.Split(line, " ") {
+			n := utf8.RuneCountInString(word)
+			if j > 0 {
+				if column+1+n > width && column > len(prettyMargin) {
+					b.WriteString("\n")
+					b.WriteString(prettyMargin)
+					column = len(prettyMargin)
+				} else {
+					b.WriteString(" ")
+					column++
+				}
+			}
+			b.WriteString(word)
+			column += n
+		}
+	}
+
+	return b.String()
+}
+
+// value returns a colorized representation of a value, spreading
+// nested structs, maps, and collections across multiple lines
+func (p palette) value(v reflect.Value, indent string, depth int) string {
+	if v.IsValid() && v.CanInterface() {
+		v = reflect.ValueOf(values.Encode(v.Interface()))
+	}
+	if !v.IsValid() {
+		return p.null("nil")
+	}
+	if depth > prettyDepth {
+		return "..."
+	}
+	if v.Type() == rawType {
+		return v.String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return p.str(strconv.Quote(v.String()))
+	case reflect.Bool:
+		return p.boolean(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return p.number(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return p.number(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return p.number(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		return p.number(fmt.Sprint(v.Complex()))
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return p.null("nil")
+		}
+		if v.Kind() == reflect.Ptr {
+			return "&" + p.value(v.Elem(), indent, depth+1)
+		}
+
+		return p.value(v.Elem(), indent, depth+1)
+	case reflect.Struct:
+		return p.structure(v, indent, depth)
+	case reflect.Map:
+		return p.mapping(v, indent, depth)
+	case reflect.Slice, reflect.Array:
+		return p.collection(v, indent, depth)
+	default:
+		return fmt.Sprintf("%#v", v)
+	}
+}
+
+// structure returns a representation of a struct with one field per line
+func (p palette) structure(v reflect.Value, indent string, depth int) string {
+	if v.NumField() == 0 {
+		return v.Type().String() + "{}"
+	}
+
+	inner := indent + prettyIndent
+	var b strings.Builder
+	b.WriteString(v.Type().String())
+	b.WriteString("{\n")
+	for i := 0; i < v.NumField(); i++ {
+		b.WriteString(inner)
+		b.WriteString(p.key(v.Type().Field(i).Name))
+		b.WriteString(": ")
+		b.WriteString(p.value(v.Field(i), inner, depth+1))
+		b.WriteString(",\n")
+	}
+	b.WriteString(indent)
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// mapping returns a representation of a map with one entry per line, sorted by key
+func (p palette) mapping(v reflect.Value, indent string, depth int) string {
+	if v.IsNil() {
+		return p.null("nil")
+	}
+	if v.Len() == 0 {
+		return v.Type().String() + "{}"
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	inner := indent + prettyIndent
+	var b strings.Builder
+	b.WriteString(v.Type().String())
+	b.WriteString("{\n")
+	for _, key := range keys {
+		b.WriteString(inner)
+		b.WriteString(p.value(key, inner, depth+1))
+		b.WriteString(": ")
+		b.WriteString(p.value(v.MapIndex(key), inner, depth+1))
+		b.WriteString(",\n")
+	}
+	b.WriteString(indent)
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// collection returns a representation of a slice or array, which
+// only spans multiple lines if its elements are not scalars
+func (p palette) collection(v reflect.Value, indent string, depth int) string {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return p.null("nil")
+	}
+
+	items := make([]string, v.Len())
+	inline := true
+	for i := range items {
+		items[i] = p.value(v.Index(i), indent+prettyIndent, depth+1)
+		inline = inline && isScalar(v.Index(i))
+	}
+
+	if inline {
+		return v.Type().String() + "{" + strings.Join(items, ", ") + "}"
+	}
+
+	var b strings.Builder
+	b.WriteString(v.Type().String())
+	b.WriteString("{\n")
+	for _, item := range items {
+		b.WriteString(indent + prettyIndent)
+		b.WriteString(item)
+		b.WriteString(",\n")
+	}
+	b.WriteString(indent)
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// isScalar returns true if a value is printed on a single line
+func isScalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return false
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil() || isScalar(v.Elem())
+	default:
+		return true
+	}
+}
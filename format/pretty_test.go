@@ -0,0 +1,123 @@
+package format_test
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/preflight"
+)
+
+type officer struct {
+	Name string
+	Rank string
+}
+
+func TestPretty(test *testing.T) {
+	t := preflight.Unit(test)
+	test.Setenv("COLUMNS", "100")
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "01-02-2006 15:04:05 MST",
+			Name:       "captainslog",
+			Text:       "starship enterprise",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Pretty,
+			Data: []msg.Field{
+				msg.String("captain", "picard"),
+				msg.Any("first officer", officer{"riker", "commander"}),
+				msg.Int("crew", 1012),
+			},
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("  info :: 08-28-2019 12:32:24 PST :: captainslog :: starship enterprise\n" +
+		"          captain       = \"picard\"\n" +
+		"          first officer = format_test.officer{\n" +
+		"                              Name: \"riker\",\n" +
+		"                              Rank: \"commander\",\n" +
+		"                          }\n" +
+		"          crew          = 1012\n")
+}
+
+func TestPrettyWrap(test *testing.T) {
+	t := preflight.Unit(test)
+	test.Setenv("COLUMNS", "40")
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		message := &msg.Message{
+			Time:       stardate,
+			TimeFormat: "15:04",
+			Name:       "log",
+			Text:       "to boldly go where\nno one has gone before",
+			Level:      levels.Info,
+			Threshold:  levels.Info,
+			Stdout:     stdout,
+			Print:      format.Pretty,
+		}
+
+		message.Print(message)
+
+	})
+	defer w.Close()
+
+	w.Text().Equals("  info :: 12:32 :: log :: to boldly go\n" +
+		"          where\n" +
+		"          no one has gone before\n")
+}
+
+func TestPrettyConcurrent(test *testing.T) {
+	t := preflight.Unit(test)
+	test.Setenv("COLUMNS", "100")
+
+	w := t.ExpectWritten(func(stdout *os.File) {
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				message := &msg.Message{
+					Time:       stardate,
+					TimeFormat: "15:04",
+					Name:       "log",
+					Text:       "engage",
+					Level:      levels.Info,
+					Threshold:  levels.Info,
+					Stdout:     stdout,
+					Print:      format.Pretty,
+					Data: []msg.Field{
+						msg.String("captain", "picard"),
+						msg.Int("warp", 9),
+					},
+				}
+				for j := 0; j < 20; j++ {
+					message.Print(message)
+				}
+			}()
+		}
+		wg.Wait()
+
+	})
+	defer w.Close()
+
+	// each message should be written at once, so records are not interleaved
+	record := "  info :: 12:32 :: log :: engage\n" +
+		"          captain = \"picard\"\n" +
+		"          warp    = 9\n"
+	w.Text().Equals(strings.Repeat(record, 20*20))
+}
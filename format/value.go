@@ -0,0 +1,312 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vincent.click/pkg/captainslog/v2/values"
+)
+
+// maximum depth of nested values in text and JSON
+const valueDepth = 8
+
+// encode returns a value with the encoding from the values package, if it can be read
+func encode(v reflect.Value) reflect.Value {
+	if v.IsValid() && v.CanInterface() {
+		return reflect.ValueOf(values.Encode(v.Interface()))
+	}
+
+	return v
+}
+
+// appendGoValue appends a value in Go syntax, encoding the values nested in
+// structs, maps, and collections as well
+func appendGoValue(b []byte, v reflect.Value, depth int) []byte {
+	v = encode(v)
+	if !v.IsValid() {
+		return append(b, "nil"...)
+	}
+	if depth > valueDepth {
+		return append(b, "..."...)
+	}
+	if v.Type() == rawType {
+		return append(b, v.String()...)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.AppendQuote(b, v.String())
+	case reflect.Bool:
+		return strconv.AppendBool(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(b, v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(b, v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return append(b, "nil"...)
+		}
+		if v.Kind() == reflect.Ptr {
+			b = append(b, '&')
+		}
+
+		return appendGoValue(b, v.Elem(), depth+1)
+	case reflect.Struct:
+		return appendGoStruct(b, v, depth)
+	case reflect.Map:
+		return appendGoMap(b, v, depth)
+	case reflect.Slice, reflect.Array:
+		return appendGoCollection(b, v, depth)
+	default:
+		return append(b, fmt.Sprintf("%#v", v)...)
+	}
+}
+
+// appendGoStruct appends a struct in Go syntax, with the names of its fields
+func appendGoStruct(b []byte, v reflect.Value, depth int) []byte {
+	b = append(b, v.Type().String()...)
+	b = append(b, '{')
+	for i := 0; i < v.NumField(); i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, v.Type().Field(i).Name...)
+		b = append(b, ':')
+		b = appendGoValue(b, v.Field(i), depth+1)
+	}
+
+	return append(b, '}')
+}
+
+// appendGoCollection appends a slice or array in Go syntax
+func appendGoCollection(b []byte, v reflect.Value, depth int) []byte {
+	b = append(b, v.Type().String()...)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return append(b, "(nil)"...)
+	}
+
+	b = append(b, '{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendGoValue(b, v.Index(i), depth+1)
+	}
+
+	return append(b, '}')
+}
+
+// appendGoMap appends a map in Go syntax, sorted by key
+func appendGoMap(b []byte, v reflect.Value, depth int) []byte {
+	b = append(b, v.Type().String()...)
+	if v.IsNil() {
+		return append(b, "(nil)"...)
+	}
+
+	b = append(b, '{')
+	for i, key := range sortedKeys(v) {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendGoValue(b, key, depth+1)
+		b = append(b, ':')
+		b = appendGoValue(b, v.MapIndex(key), depth+1)
+	}
+
+	return append(b, '}')
+}
+
+// appendJSONValue appends a value as JSON, encoding the values nested in
+// structs, maps, and collections as well
+func appendJSONValue(b []byte, v reflect.Value, depth int) []byte {
+	v = encode(v)
+	if !v.IsValid() {
+		return append(b, "null"...)
+	}
+	if depth > valueDepth {
+		return appendJSONString(b, "...")
+	}
+	if v.Type() == rawType {
+		return append(b, v.String()...)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return appendJSONString(b, v.String())
+	case reflect.Bool:
+		return strconv.AppendBool(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(b, v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return appendJSONString(b, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+
+		return strconv.AppendFloat(b, f, 'g', -1, v.Type().Bits())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return append(b, "null"...)
+		}
+
+		return appendJSONValue(b, v.Elem(), depth+1)
+	case reflect.Struct:
+		b = append(b, '{')
+		b, _ = appendJSONMembers(b, v, 0, depth)
+
+		return append(b, '}')
+	case reflect.Map:
+		return appendJSONMap(b, v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return append(b, "null"...)
+		}
+		b = append(b, '[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONValue(b, v.Index(i), depth+1)
+		}
+
+		return append(b, ']')
+	default:
+		return appendJSONString(b, fmt.Sprintf("%#v", v))
+	}
+}
+
+// appendJSONMembers appends the exported fields of a struct as the members of
+// a JSON object, following their json tags and inlining embedded structs,
+// and returns the total number of members written so far
+func appendJSONMembers(b []byte, v reflect.Value, n int, depth int) ([]byte, int) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := splitTag(tag)
+		value := v.Field(i)
+		if field.Anonymous && len(name) == 0 && indirect(field.Type).Kind() == reflect.Struct {
+			if value.Kind() == reflect.Ptr && !value.IsNil() {
+				b, n = appendJSONMembers(b, value.Elem(), n, depth)
+			} else if value.Kind() == reflect.Struct {
+				b, n = appendJSONMembers(b, value, n, depth)
+			}
+
+			continue
+		}
+		if field.PkgPath != "" || (strings.Contains(opts, "omitempty") && isEmpty(value)) {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		if n > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, name)
+		b = append(b, ':')
+		b = appendJSONValue(b, value, depth+1)
+		n++
+	}
+
+	return b, n
+}
+
+// appendJSONMap appends a map as a JSON object, sorted by key
+func appendJSONMap(b []byte, v reflect.Value, depth int) []byte {
+	if v.IsNil() {
+		return append(b, "null"...)
+	}
+
+	b = append(b, '{')
+	for i, key := range sortedKeys(v) {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, keyText(key))
+		b = append(b, ':')
+		b = appendJSONValue(b, v.MapIndex(key), depth+1)
+	}
+
+	return append(b, '}')
+}
+
+// sortedKeys returns the keys of a map sorted by number, or else by their text
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		switch keys[i].Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return keys[i].Int() < keys[j].Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return keys[i].Uint() < keys[j].Uint()
+		case reflect.Float32, reflect.Float64:
+			return keys[i].Float() < keys[j].Float()
+		default:
+			return keyText(keys[i]) < keyText(keys[j])
+		}
+	})
+
+	return keys
+}
+
+// keyText returns the text of a map key, with the encoding from the values package
+func keyText(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+
+	return fmt.Sprint(encode(key))
+}
+
+// indirect returns the type that a pointer type points to, or the type itself
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// splitTag returns the name and the options of a json tag
+func splitTag(tag string) (name string, opts string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+
+	return tag, ""
+}
+
+// isEmpty returns true if a value is omitted by the omitempty option of a json tag
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,59 @@
+package format
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultWidth is the line width used when the terminal size is unknown
+const DefaultWidth = 80
+
+// widths of the standard streams, which are read again when the terminal
+// is resized; 0 if they have not been read
+var widths struct {
+	watch  sync.Once
+	stdout int64
+	stderr int64
+}
+
+// Width returns the number of columns available on a stream
+func Width(stream *os.File) int {
+	if cols, ok := terminalWidth(stream); ok && cols > 0 {
+		return cols
+	}
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+
+	return DefaultWidth
+}
+
+// streamWidth returns the width of a stream, which is cached for the standard streams
+func streamWidth(stream *os.File) int {
+	var cached *int64
+	switch stream {
+	case os.Stdout:
+		cached = &widths.stdout
+	case os.Stderr:
+		cached = &widths.stderr
+	default:
+		return Width(stream)
+	}
+
+	widths.watch.Do(watchResize)
+	if cols := atomic.LoadInt64(cached); cols > 0 {
+		return int(cols)
+	}
+	cols := Width(stream)
+	atomic.StoreInt64(cached, int64(cols))
+
+	return cols
+}
+
+// resetWidths makes the widths of the standard streams be read again
+func resetWidths() {
+	atomic.StoreInt64(&widths.stdout, 0)
+	atomic.StoreInt64(&widths.stderr, 0)
+}
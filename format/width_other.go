@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package format
+
+import (
+	"os"
+)
+
+// terminalWidth is not supported on this platform
+func terminalWidth(stream *os.File) (int, bool) {
+	return 0, false
+}
+
+// watchResize is not supported on this platform
+func watchResize() {}
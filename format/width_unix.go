@@ -0,0 +1,32 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package format
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalWidth returns the width of the terminal attached to a stream
+func terminalWidth(stream *os.File) (int, bool) {
+	size, err := unix.IoctlGetWinsize(int(stream.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(size.Col), true
+}
+
+// watchResize resets the cached widths whenever the terminal is resized
+func watchResize() {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, unix.SIGWINCH)
+	go func() {
+		for range resized {
+			resetWidths()
+		}
+	}()
+}
@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package format
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalWidth returns the width of the console attached to a stream
+func terminalWidth(stream *os.File) (int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(stream.Fd()), &info); err != nil {
+		return 0, false
+	}
+
+	return int(info.Window.Right-info.Window.Left) + 1, true
+}
+
+// watchResize does nothing, since consoles do not signal when they are resized
+func watchResize() {}
@@ -2,9 +2,29 @@ package format
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
+// separator between parts of a message
+const separator = " :: "
+
+// UnixMillis is a time layout for the number of milliseconds since the Unix epoch
+const UnixMillis = "unixmillis"
+
 // Write a string to a stream
 func Write(stream *os.File, str string) {
 	_, _ = stream.WriteString(str)
 }
+
+// appendTime appends a time formatted with a layout, or RFC 3339 if no layout is given
+func appendTime(b []byte, t time.Time, layout string) []byte {
+	switch layout {
+	case UnixMillis:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case "":
+		return t.AppendFormat(b, time.RFC3339)
+	default:
+		return t.AppendFormat(b, layout)
+	}
+}
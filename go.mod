@@ -3,10 +3,7 @@ module vincent.click/pkg/captainslog/v2
 go 1.17
 
 require (
-	github.com/fatih/color v1.12.0
-	github.com/mattn/go-isatty v0.0.13 // indirect
-	golang.org/x/sys v0.0.0-20210902050250-f475640dd07b // indirect
+	github.com/mattn/go-isatty v0.0.13
+	golang.org/x/sys v0.0.0-20210902050250-f475640dd07b
 	vincent.click/pkg/preflight v0.0.4
 )
-
-require github.com/mattn/go-colorable v0.1.8 // indirect
@@ -0,0 +1,45 @@
+package goroutine
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+)
+
+// header that the stack trace of a goroutine starts with, before its ID
+var header = []byte("goroutine ")
+
+// buffers for stack headers, which would otherwise escape to the heap
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return new([64]byte)
+	},
+}
+
+// ID returns the ID of the calling goroutine, parsed from the header of its
+// stack trace, or 0 if it cannot be parsed. It takes a few microseconds, so
+// a long-lived goroutine that logs often should call it once and add the ID to
+// a logger with With rather than look it up for every message.
+func ID() uint64 {
+	buf := buffers.Get().(*[64]byte)
+	defer buffers.Put(buf)
+
+	return parse(buf[:runtime.Stack(buf[:], false)])
+}
+
+// parse returns the ID in the header of a stack trace, as in "goroutine 42 [running]:"
+func parse(stack []byte) uint64 {
+	if !bytes.HasPrefix(stack, header) {
+		return 0
+	}
+
+	var id uint64
+	for _, c := range stack[len(header):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+
+	return id
+}
@@ -0,0 +1,46 @@
+package goroutine_test
+
+import (
+	"sync"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2/goroutine"
+	"vincent.click/pkg/preflight"
+)
+
+func TestID(test *testing.T) {
+	t := preflight.Unit(test)
+
+	id := goroutine.ID()
+	t.Expect(id > 0).Equals(true)
+	t.Expect(goroutine.ID()).Equals(id)
+
+	// other goroutines should have other IDs
+	var other uint64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		other = goroutine.ID()
+	}()
+	wg.Wait()
+
+	t.Expect(other > 0).Equals(true)
+	t.Expect(other == id).Equals(false)
+}
+
+func BenchmarkID(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		goroutine.ID()
+	}
+}
+
+func BenchmarkIDParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			goroutine.ID()
+		}
+	})
+}
@@ -10,3 +10,6 @@ const (
 	Fatal int = iota
 	Quiet int = iota
 )
+
+// Inherit is a level that defers to the parent of a logger
+const Inherit int = -1
@@ -0,0 +1,51 @@
+package levels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// names of the log levels
+var names = []string{
+	"trace",
+	"debug",
+	"info",
+	"warn",
+	"error",
+	"fatal",
+	"quiet",
+}
+
+// Name returns the name of a log level
+func Name(level int) string {
+	if level == Inherit {
+		return "inherit"
+	}
+	if level >= 0 && level < len(names) {
+		return names[level]
+	}
+
+	return strconv.Itoa(level)
+}
+
+// Parse returns the log level with a case-insensitive name or number
+func Parse(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for level, n := range names {
+		if name == n {
+			return level, nil
+		}
+	}
+	switch name {
+	case "inherit":
+		return Inherit, nil
+	case "warning":
+		return Warn, nil
+	}
+	if level, err := strconv.Atoi(name); err == nil {
+		return level, nil
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
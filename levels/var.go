@@ -0,0 +1,49 @@
+package levels
+
+import "sync/atomic"
+
+// Var is a log level that can be changed safely while logging.
+// Loggers that share it see changes immediately. A nil Var holds Inherit.
+type Var struct {
+	level int64
+}
+
+// NewVar returns a variable that holds a log level
+func NewVar(level int) *Var {
+	return &Var{level: int64(level)}
+}
+
+// Get returns the log level
+func (v *Var) Get() int {
+	if v == nil {
+		return Inherit
+	}
+
+	return int(atomic.LoadInt64(&v.level))
+}
+
+// Set changes the log level
+func (v *Var) Set(level int) {
+	atomic.StoreInt64(&v.level, int64(level))
+}
+
+// String returns the name of the log level
+func (v *Var) String() string {
+	return Name(v.Get())
+}
+
+// MarshalText returns the name of the log level
+func (v *Var) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText changes the log level to the one with a name or number
+func (v *Var) UnmarshalText(text []byte) error {
+	level, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	v.Set(level)
+
+	return nil
+}
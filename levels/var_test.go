@@ -0,0 +1,74 @@
+package levels_test
+
+import (
+	"sync"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/preflight"
+)
+
+func TestVar(test *testing.T) {
+	t := preflight.Unit(test)
+
+	level := levels.NewVar(levels.Info)
+	t.Expect(level.Get()).Equals(levels.Info)
+	t.Expect(level.String()).Equals("info")
+
+	level.Set(levels.Error)
+	t.Expect(level.Get()).Equals(levels.Error)
+
+	text, err := level.MarshalText()
+	t.Expect(err).Is().Nil()
+	t.Expect(string(text)).Equals("error")
+
+	t.Expect(level.UnmarshalText([]byte("TRACE"))).Is().Nil()
+	t.Expect(level.Get()).Equals(levels.Trace)
+	t.Expect(level.UnmarshalText([]byte("loud"))).Is().Not().Nil()
+	t.Expect(level.Get()).Equals(levels.Trace)
+}
+
+func TestVarConcurrency(test *testing.T) {
+	t := preflight.Unit(test)
+
+	level := levels.NewVar(levels.Trace)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				level.Set((i + j) % levels.Quiet)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = level.Get()
+			}
+		}()
+	}
+	wg.Wait()
+
+	t.Expect(level.Get() < levels.Quiet).Equals(true)
+}
+
+func TestParse(test *testing.T) {
+	t := preflight.Unit(test)
+
+	for name, expected := range map[string]int{
+		"trace":   levels.Trace,
+		" Debug ": levels.Debug,
+		"INFO":    levels.Info,
+		"warning": levels.Warn,
+		"4":       levels.Error,
+		"inherit": levels.Inherit,
+	} {
+		level, err := levels.Parse(name)
+		t.Expect(err).Is().Nil()
+		t.Expect(level).Equals(expected)
+	}
+
+	_, err := levels.Parse("loud")
+	t.Expect(err).Is().Not().Nil()
+}
@@ -0,0 +1,275 @@
+package limit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// Action is what a limiter does with messages over the limit
+type Action int
+
+// Actions
+const (
+	// Drop discards messages over the limit
+	Drop Action = iota
+	// Downgrade lowers the level of messages over the limit, which
+	// discards them if they fall below the logger's level
+	Downgrade
+	// Aggregate discards messages over the limit and reports how many
+	// were discarded once the limit allows messages again
+	Aggregate
+)
+
+// bucket holds the tokens for one logger or call site
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// messages aggregated since the bucket ran out
+	aggregated uint64
+	first      time.Time
+	latest     time.Time
+	// properties used to print the report, from the last aggregated message
+	template msg.Message
+	// prints the report if no message is allowed once the limit allows messages again
+	timer *time.Timer
+}
+
+// Limiter is a filter that limits the rate of messages using token buckets,
+// either for all messages or for each call site. It is safe to share
+// between loggers.
+type Limiter struct {
+	// number of messages allowed per second
+	Rate float64
+	// number of messages allowed in a burst
+	Burst int
+	// use a separate bucket for each call site
+	PerCallSite bool
+	// what to do with messages over the limit
+	Action Action
+	// level that messages over the limit are downgraded to
+	Level int
+	// clock that stamps the reports printed by Flush; set it to the
+	// clock of the logger so that they are in order with its messages
+	Clock clock.Clock
+
+	mutex   sync.Mutex
+	buckets map[uintptr]*bucket
+
+	allowed uint64
+	limited uint64
+}
+
+// New returns a limiter that drops messages over a rate per second
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		Rate:    rate,
+		Burst:   burst,
+		Action:  Drop,
+		Level:   levels.Trace,
+		Clock:   clock.System,
+		buckets: map[uintptr]*bucket{},
+	}
+}
+
+// Allowed returns the number of messages within the limit
+func (l *Limiter) Allowed() uint64 {
+	return atomic.LoadUint64(&l.allowed)
+}
+
+// Limited returns the number of messages over the limit
+func (l *Limiter) Limited() uint64 {
+	return atomic.LoadUint64(&l.limited)
+}
+
+// NeedsSite returns true if the limiter uses a bucket for each call site
+func (l *Limiter) NeedsSite() bool {
+	return l.PerCallSite
+}
+
+// Allow returns true if a message should be printed. With Aggregate, the
+// report of the messages over the limit is printed before the next message
+// that is allowed, or by Flush once the limit allows messages again.
+func (l *Limiter) Allow(message *msg.Message) bool {
+	var site uintptr
+	if l.PerCallSite {
+		site = message.PC
+	}
+
+	l.mutex.Lock()
+	b := l.bucket(site, message.Time)
+	ok := b.take(message.Time, l.Rate, l.Burst)
+	var summary *msg.Message
+	if ok {
+		summary = b.report(message.Time)
+	} else if l.Action == Aggregate {
+		l.aggregate(site, b, message)
+	}
+	l.mutex.Unlock()
+
+	printReport(summary)
+	if ok {
+		atomic.AddUint64(&l.allowed, 1)
+
+		return true
+	}
+
+	atomic.AddUint64(&l.limited, 1)
+	if l.Action == Downgrade && l.Level < message.Level {
+		message.Level = l.Level
+
+		return message.Level >= message.Threshold
+	}
+
+	return false
+}
+
+// Flush prints the reports of the messages aggregated in every bucket,
+// stamped with the time of the clock
+func (l *Limiter) Flush() {
+	now := l.now()
+
+	l.mutex.Lock()
+	sites := make([]uintptr, 0, len(l.buckets))
+	for site := range l.buckets {
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i] < sites[j]
+	})
+	reports := make([]*msg.Message, 0, len(sites))
+	for _, site := range sites {
+		reports = append(reports, l.buckets[site].report(now))
+	}
+	l.mutex.Unlock()
+
+	for _, report := range reports {
+		printReport(report)
+	}
+}
+
+// flush prints the report of the messages aggregated in the bucket of a call site
+func (l *Limiter) flush(site uintptr) {
+	now := l.now()
+
+	l.mutex.Lock()
+	var report *msg.Message
+	if b, ok := l.buckets[site]; ok {
+		report = b.report(now)
+	}
+	l.mutex.Unlock()
+
+	printReport(report)
+}
+
+// now returns the current time according to the clock
+func (l *Limiter) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock.Now()
+	}
+
+	return clock.System.Now()
+}
+
+// aggregate counts a message over the limit and schedules a report of it
+// for when the bucket has a token again
+func (l *Limiter) aggregate(site uintptr, b *bucket, message *msg.Message) {
+	if b.aggregated == 0 {
+		b.first = message.Time
+	}
+	b.aggregated++
+	b.latest = message.Time
+	b.template = msg.Message{
+		TimeFormat: message.TimeFormat,
+		Name:       message.Name,
+		Threshold:  message.Threshold,
+		HasColor:   message.HasColor,
+		ForceColor: message.ForceColor,
+		Theme:      message.Theme,
+		Labels:     message.Labels,
+		Stdout:     message.Stdout,
+		Stderr:     message.Stderr,
+		Print:      message.Print,
+	}
+	if b.timer == nil && l.Rate > 0 {
+		wait := time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+		b.timer = time.AfterFunc(wait, func() {
+			l.flush(site)
+		})
+	}
+}
+
+// bucket returns the bucket for a call site, creating a full one if needed
+func (l *Limiter) bucket(site uintptr, now time.Time) *bucket {
+	if l.buckets == nil {
+		l.buckets = map[uintptr]*bucket{}
+	}
+	b, ok := l.buckets[site]
+	if !ok {
+		b = &bucket{
+			tokens: float64(l.Burst),
+			last:   now,
+		}
+		l.buckets[site] = b
+	}
+
+	return b
+}
+
+// take refills the bucket and takes a token if there is one
+func (b *bucket) take(now time.Time, rate float64, burst int) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// report returns a message reporting the messages that were aggregated in
+// the bucket, or nil if there are none or the report is below the threshold
+func (b *bucket) report(now time.Time) *msg.Message {
+	if b.aggregated == 0 {
+		return nil
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	summary := b.template
+	summary.Time = now
+	summary.Text = fmt.Sprintf("rate limited %d messages", b.aggregated)
+	summary.Template = "rate limited %d messages"
+	summary.Level = levels.Warn
+	summary.Data = []msg.Field{
+		msg.Int64("count", int64(b.aggregated)),
+		msg.Duration("span", b.latest.Sub(b.first)),
+	}
+	b.aggregated = 0
+	if summary.Level < summary.Threshold {
+		return nil
+	}
+
+	return &summary
+}
+
+// printReport prints a report, if there is one
+func printReport(report *msg.Message) {
+	if report != nil {
+		report.Print(report)
+	}
+}
@@ -0,0 +1,160 @@
+package limit_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/limit"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/preflight"
+)
+
+var stardate = time.Date(2364, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestDrop(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(2, 3)
+
+	// should allow a burst
+	for i := 0; i < 3; i++ {
+		t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate))).Equals(true)
+	}
+	t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate))).Equals(false)
+
+	// should refill at the rate
+	t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate.Add(500*time.Millisecond)))).Equals(true)
+	t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate.Add(500*time.Millisecond)))).Equals(false)
+
+	t.Expect(limiter.Allowed()).Equals(uint64(4))
+	t.Expect(limiter.Limited()).Equals(uint64(2))
+}
+
+func TestPerCallSite(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(1, 1)
+	limiter.PerCallSite = true
+
+	t.Expect(limiter.NeedsSite()).Equals(true)
+	t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate))).Equals(true)
+	t.Expect(limiter.Allow(createMessage(levels.Info, 1, stardate))).Equals(false)
+	t.Expect(limiter.Allow(createMessage(levels.Info, 2, stardate))).Equals(true)
+}
+
+func TestDowngrade(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(1, 1)
+	limiter.Action = limit.Downgrade
+	limiter.Level = levels.Debug
+
+	t.Expect(limiter.Allow(createMessage(levels.Warn, 1, stardate))).Equals(true)
+
+	message := createMessage(levels.Warn, 1, stardate)
+	t.Expect(limiter.Allow(message)).Equals(true)
+	t.Expect(message.Level).Equals(levels.Debug)
+
+	message = createMessage(levels.Warn, 1, stardate)
+	message.Threshold = levels.Info
+	t.Expect(limiter.Allow(message)).Equals(false)
+}
+
+func TestAggregate(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(1, 1)
+	limiter.Action = limit.Aggregate
+
+	var summaries []*msg.Message
+	send := func(offset time.Duration) bool {
+		message := createMessage(levels.Error, 1, stardate.Add(offset))
+		message.Print = func(summary *msg.Message) {
+			summaries = append(summaries, summary)
+		}
+
+		return limiter.Allow(message)
+	}
+
+	t.Expect(send(0)).Equals(true)
+	t.Expect(send(100 * time.Millisecond)).Equals(false)
+	t.Expect(send(300 * time.Millisecond)).Equals(false)
+	t.Expect(summaries).HasLength(0)
+
+	t.Expect(send(time.Second)).Equals(true)
+	t.Expect(summaries).HasLength(1)
+	t.Expect(summaries[0].Text).Equals("rate limited 2 messages")
+	t.Expect(summaries[0].Data).Equals([]msg.Field{
+		msg.Int64("count", 2),
+		msg.Duration("span", 200*time.Millisecond),
+	})
+}
+
+func TestAggregateThreshold(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(1, 1)
+	limiter.Action = limit.Aggregate
+
+	printed := 0
+	for _, offset := range []time.Duration{0, 100 * time.Millisecond, time.Second} {
+		message := createMessage(levels.Error, 1, stardate.Add(offset))
+		message.Threshold = levels.Error
+		message.Print = func(*msg.Message) {
+			printed++
+		}
+		limiter.Allow(message)
+	}
+
+	// a warning should not be printed by a logger that only prints errors
+	t.Expect(printed).Equals(0)
+}
+
+func TestFlush(test *testing.T) {
+	t := preflight.Unit(test)
+
+	limiter := limit.New(50, 1)
+	limiter.Action = limit.Aggregate
+	limiter.Clock = clock.Fixed(stardate.Add(time.Hour))
+
+	summaries := make(chan *msg.Message, 2)
+	for _, offset := range []time.Duration{0, time.Millisecond} {
+		message := createMessage(levels.Error, 1, stardate.Add(offset))
+		message.Print = func(summary *msg.Message) {
+			summaries <- summary
+		}
+		limiter.Allow(message)
+	}
+
+	// messages before a quiet period should still be reported
+	select {
+	case summary := <-summaries:
+		t.Expect(summary.Text).Equals("rate limited 1 messages")
+		t.Expect(summary.Time).Equals(stardate.Add(time.Hour))
+	case <-time.After(5 * time.Second):
+		t.T.Fatal("summary was not printed")
+	}
+
+	limiter.Flush()
+	t.Expect(summaries).HasLength(0)
+}
+
+/**
+ * Test Helpers
+ */
+func createMessage(level int, site uintptr, now time.Time) *msg.Message {
+	return &msg.Message{
+		Time:      now,
+		Name:      "captainslog",
+		Text:      "captainslog",
+		Template:  "captainslog",
+		Level:     level,
+		Threshold: levels.Trace,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		PC:        site,
+	}
+}
@@ -1,13 +1,16 @@
 package captainslog
 
 import (
+	"errors"
 	"os"
 	"time"
 
 	"vincent.click/pkg/captainslog/v2/caller"
+	"vincent.click/pkg/captainslog/v2/clock"
 	"vincent.click/pkg/captainslog/v2/format"
 	"vincent.click/pkg/captainslog/v2/levels"
 	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/vmodule"
 )
 
 // Defaults
@@ -15,31 +18,63 @@ const (
 	ISO8601 = "01-02-2006 15:04:05 MST"
 )
 
+// ErrNoLevel is returned when changing the level of a logger that has none
+// while it may be logging, since giving it one then would not be safe
+var ErrNoLevel = errors.New("logger has no level that can be changed while logging")
+
 // Logger is an object for logging
 type Logger struct {
 	// name of the logger; leave empty to log the current function
-	Name     string
-	Level    int
+	Name string
+	// minimum level of messages that are logged, or levels.Inherit. It is
+	// shared with the loggers derived with With, and changing it with
+	// SetLevel is safe while logging. A nil level is inherited.
+	Level *levels.Var
+	// whether colors may be used; they are used on streams that are terminals,
+	// unless the environment sets NO_COLOR or FORCE_COLOR or TERM=dumb
 	HasColor bool
-	// layout string used to format the time. See https://pkg.go.dev/time?tab=doc#Time.Format
+	// use colors even if the streams or the environment do not support them
+	ForceColor bool
+	// styles of colored logs, such as msg.Dark or msg.Light
+	Theme *msg.Theme
+	// names of the levels in text formats, such as msg.Uppercase or msg.Short
+	Labels *msg.Labels
+	// layout string used by text formats to print the time. See https://pkg.go.dev/time?tab=doc#Time.Format
+	// or use format.UnixMillis for the number of milliseconds since the Unix epoch
 	TimeFormat string
 	// maximum caller name length to display
 	NameCutoff int
 	Stdout     *os.File
 	Stderr     *os.File
 	Format     msg.Format
+	// source of the time for each message
+	Clock clock.Clock
+	// hooks that inspect and modify messages before they are printed, in order
+	Hooks []msg.Hook
+	// filters that decide whether messages are printed, such as a sampler
+	Filters []msg.Filter
+	// rules that override the level by the package or file of the call site
+	VModule *vmodule.Spec
+	// add the ID of the logging goroutine to every message that is logged,
+	// which costs a few microseconds per message
+	Goroutine bool
+	// fields added to every message
+	fields []msg.Field
+	// logger that unset options are inherited from
+	parent *Logger
 }
 
 // NewLogger returns a new logger with the specified minimum logging level
 func NewLogger() *Logger {
 	return &Logger{
 		HasColor:   true,
-		Level:      levels.Debug,
+		Level:      levels.NewVar(levels.Debug),
 		TimeFormat: ISO8601,
 		NameCutoff: 15,
 		Stdout:     os.Stdout,
 		Stderr:     os.Stderr,
 		Format:     format.Flat,
+		Clock:      clock.System,
 	}
 }
 
@@ -52,24 +87,100 @@ func (log *Logger) name() string {
 	return caller.Shorten(caller.GetName(4), log.NameCutoff)
 }
 
+// now returns the current time according to the logger's clock
+func (log *Logger) now() time.Time {
+	for l := log; l != nil; l = l.Parent() {
+		if l.Clock != nil {
+			return l.Clock.Now()
+		}
+	}
+
+	return time.Now()
+}
+
+// needsSite returns true if any filter needs the call site of messages
+func (log *Logger) needsSite() bool {
+	for _, filter := range log.Filters {
+		if f, ok := filter.(msg.SiteFilter); ok && f.NeedsSite() {
+			return true
+		}
+	}
+
+	return false
+}
+
 // message returns a new message
 func (log *Logger) message() *msg.Message {
 	msg := msg.MsgPool.Get().(*msg.Message)
-	msg.Time = time.Now().Format(log.TimeFormat)
+	streams := log.streams()
+	msg.Time = log.now()
+	msg.TimeFormat = log.timeFormat()
 	msg.Name = log.name()
-	msg.Stdout = log.Stdout
-	msg.Stderr = log.Stderr
-	msg.HasColor = log.HasColor
-	msg.Threshold = log.Level
-	msg.Print = log.Format
-	msg.Data = []interface{}{}
+	msg.Stdout = streams.Stdout
+	msg.Stderr = streams.Stderr
+	msg.HasColor = streams.HasColor
+	msg.ForceColor = streams.ForceColor
+	msg.Theme = streams.Theme
+	msg.Labels = streams.Labels
+	msg.Print = log.format()
+	msg.Hooks = log.Hooks
+	msg.Filters = log.Filters
+	msg.RecordGoroutine = log.Goroutine
+	msg.PC = 0
+	spec := log.vmodule()
+	if spec != nil || log.needsSite() {
+		msg.PC = caller.PC(3)
+	}
+	msg.Threshold = log.threshold(spec, msg.PC)
+	msg.Data = append(msg.Data[:0], log.fields...)
 
 	return msg
 }
 
+// With returns a copy of the logger that adds fields to every message
+func (log *Logger) With(fields ...msg.Field) *Logger {
+	child := *log
+	child.fields = append(log.fields[:len(log.fields):len(log.fields)], fields...)
+
+	return &child
+}
+
+// WithGroup returns a copy of the logger that nests the fields
+// of every message under a name
+func (log *Logger) WithGroup(name string) *Logger {
+	return log.With(msg.OpenGroup(name))
+}
+
+// AddHook adds a hook that runs after the existing ones, only for the given
+// levels if any are specified. Child loggers created afterwards inherit it.
+func (log *Logger) AddHook(hook msg.Hook, levels ...int) {
+	if len(levels) > 0 {
+		hook = msg.OnLevels(hook, levels...)
+	}
+	log.Hooks = append(log.Hooks[:len(log.Hooks):len(log.Hooks)], hook)
+}
+
+// SetLevel changes the minimum level of messages that are logged, which is
+// safe while logging and seen by derived loggers. A logger without a Level is
+// given one, which is not safe while logging; loggers returned by NewLogger
+// and Get always have one.
+func (log *Logger) SetLevel(level int) {
+	if log.Level == nil {
+		log.Level = levels.NewVar(level)
+
+		return
+	}
+	log.Level.Set(level)
+}
+
+// GetLevel returns the level set on the logger, which may be levels.Inherit
+func (log *Logger) GetLevel() int {
+	return log.Level.Get()
+}
+
 // I returns a single field that can be added to logs
 func (log *Logger) I(name string, value interface{}) msg.Field {
-	return msg.Field{name, value}
+	return msg.Any(name, value)
 }
 
 // Field starts a message with a data field
@@ -82,6 +193,28 @@ func (log *Logger) Fields(fields ...msg.Field) *msg.Message {
 	return log.message().Fields(fields...)
 }
 
+// Enabled returns true if messages with the given level are logged
+// from the calling function
+func (log *Logger) Enabled(level int) bool {
+	return log.enabled(level)
+}
+
+// enabled returns true if messages with the given level are logged
+// from the function that called the caller of enabled
+func (log *Logger) enabled(level int) bool {
+	if spec := log.vmodule(); spec != nil {
+		return level >= log.threshold(spec, caller.PC(3))
+	}
+
+	return level >= log.EffectiveLevel()
+}
+
+// LogFunc logs a message with the given level, calling a function
+// to produce the text only if the message is logged
+func (log *Logger) LogFunc(level int, text func() string) {
+	log.message().LogFunc(level, text)
+}
+
 // Trace logs a message with level Trace
 func (log *Logger) Trace(format string, args ...interface{}) {
 	log.message().Trace(format, args...)
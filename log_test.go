@@ -1,24 +1,37 @@
 package captainslog_test
 
 import (
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/goroutine"
 	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/limit"
+	"vincent.click/pkg/captainslog/v2/msg"
 	"vincent.click/pkg/captainslog/v2/preflight"
 	"vincent.click/pkg/captainslog/v2/preflight/log"
+	"vincent.click/pkg/captainslog/v2/sample"
 )
 
-// Patterns
-const (
-	iso8601 = "([0-9]{2}-){2}[0-9]{4} ([0-9]{2}:){2}[0-9]{2} .+"
-)
+// time of the test messages
+var stardate = time.Date(2364, 1, 2, 3, 4, 5, 0, time.FixedZone("PST", -8*60*60))
+
+// logs are parsed without colors, even in CI services that support them
+func TestMain(m *testing.M) {
+	_ = os.Setenv("NO_COLOR", "1")
+	os.Exit(m.Run())
+}
 
 func getLogger() *captainslog.Logger {
 	log := captainslog.NewLogger()
-	log.Level = levels.Trace
+	log.SetLevel(levels.Trace)
+	log.Clock = clock.UTC(clock.Fixed(stardate))
 
 	return log
 }
@@ -58,10 +71,11 @@ func TestNewLogger(test *testing.T) {
 
 	// should use default values
 	t.Expect(log.HasColor).Is().EqualTo(true)
-	t.Expect(log.Level).Equals(levels.Debug)
+	t.Expect(log.GetLevel()).Equals(levels.Debug)
 	t.Expect(log.TimeFormat).Equals(captainslog.ISO8601)
 	t.Expect(log.Stdout).Equals(os.Stdout)
 	t.Expect(log.Stderr).Equals(os.Stderr)
+	t.Expect(log.Clock).Equals(clock.System)
 }
 
 func TestLogs(test *testing.T) {
@@ -83,7 +97,7 @@ func TestLogs(test *testing.T) {
 		log.Fields.Is().Empty()
 		log.Level.Equals(level)
 		log.Message.Equals(message)
-		log.Time.Matches(iso8601)
+		log.Time.Equals("01-02-2364 11:04:05 UTC")
 		log.Name.Matches("func[0-9]+")
 	}
 
@@ -115,6 +129,8 @@ func ExampleLogger() {
 	// first to remove the path, then the method parent,
 	// then truncate
 	log.NameCutoff = 100
+	// Use a fixed clock in UTC for reproducible timestamps
+	log.Clock = clock.UTC(clock.Fixed(time.Date(2364, 1, 2, 3, 4, 5, 0, time.UTC)))
 	// Use the output streams of your choice
 	_, log.Stdout, _ = os.Pipe()
 	_, log.Stderr, _ = os.Pipe()
@@ -138,13 +154,12 @@ func TestName(test *testing.T) {
 	})
 
 	logs[0].Name.Equals(expectedName)
+	logs[0].FullText.Equals("  info :: 01-02-2364 11:04:05 UTC :: captainslog :: x")
 }
 
 func TestTimeFormat(test *testing.T) {
 	t := preflight.Unit(test)
 
-	rfc822 := "[0-9]{2} [A-Z][a-z]{2} [0-9]{2} [0-9]{2}:[0-9]{2} .+"
-
 	logs, _ := t.ExpectLogged(func(stdout *os.File, _ *os.File) {
 		log := getLogger()
 		log.Stdout = stdout
@@ -153,7 +168,7 @@ func TestTimeFormat(test *testing.T) {
 		log.Info("x")
 	})
 
-	logs[0].Time.Matches(rfc822)
+	logs[0].Time.Equals("02 Jan 64 11:04 UTC")
 }
 
 func TestExit(test *testing.T) {
@@ -228,10 +243,10 @@ func ExampleLogger_Fields() {
 	log := captainslog.NewLogger()
 
 	// log.Fields() makes structured logging easier
-	// through key-value pairs
+	// through typed key-value pairs
 	log.Fields(
-		[2]interface{}{"phasers", 1},
-		[2]interface{}{"photon torpedos", 1},
+		msg.Int("phasers", 1),
+		msg.Int("photon torpedos", 1),
 	).Warn("weapons locked")
 
 	// log.I() conveniently returns a key-value pair
@@ -266,7 +281,7 @@ func TestLevels(test *testing.T) {
 		log := getLogger()
 		log.Stdout = stdout
 		log.Stderr = stderr
-		log.Level = levels.Warn
+		log.SetLevel(levels.Warn)
 
 		log.Info("x")
 		log.Warn("x")
@@ -275,3 +290,292 @@ func TestLevels(test *testing.T) {
 	t.Expect(stdout).HasLength(0)
 	t.Expect(stderr).HasLength(1)
 }
+
+func ExampleLogger_WithGroup() {
+	log := captainslog.NewLogger()
+	log.Format = format.JSON
+
+	// Child loggers add fields to every message
+	// and can nest them in groups
+	db := log.With(msg.String("ship", "enterprise")).WithGroup("db")
+	db.Field("table", "crew").Info("query")
+}
+
+func TestNilError(test *testing.T) {
+	t := preflight.Unit(test)
+
+	logs, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+
+		// errors that are nil pointers should not panic
+		log.Fields(msg.Err("err", (*breach)(nil))).Info("scan")
+		log.Field("err", error((*breach)(nil))).Info("scan")
+	})
+
+	logs[0].Fields.Equals(`err="(*captainslog_test.breach)(nil)"`)
+	logs[1].Fields.Equals(`err="(*captainslog_test.breach)(nil)"`)
+}
+
+func TestWith(test *testing.T) {
+	t := preflight.Unit(test)
+
+	logs, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+
+		child := log.With(msg.String("captain", "picard"))
+		child.Info("energize")
+		child.Field("first officer", "riker").Info("engage")
+		log.Info("make it so")
+	})
+
+	logs[0].Fields.Equals("captain=\"picard\"")
+	logs[1].Fields.Equals("captain=\"picard\", first officer=\"riker\"")
+	logs[2].Fields.Is().Empty()
+}
+
+func TestWithGroup(test *testing.T) {
+	t := preflight.Unit(test)
+
+	logs, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+
+		db := log.With(msg.String("ship", "enterprise")).WithGroup("db")
+		db.With(msg.String("table", "crew")).WithGroup("pool").Field("size", 4).Info("query")
+		db.Info("idle")
+	})
+
+	logs[0].Fields.Equals("ship=\"enterprise\", db.table=\"crew\", db.pool.size=4")
+	logs[1].Fields.Equals("ship=\"enterprise\"")
+}
+
+func TestGoroutine(test *testing.T) {
+	t := preflight.Unit(test)
+
+	var main, other uint64
+	logs, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		log.Goroutine = true
+		main = goroutine.ID()
+
+		log.WithGroup("db").Info("query")
+
+		// filters should be able to follow a goroutine through interleaved logs
+		log.Filters = []msg.Filter{msg.Goroutines(main)}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			other = goroutine.ID()
+			log.Info("hidden")
+		}()
+		<-done
+		log.Info("engage")
+	})
+
+	t.Expect(logs).HasLength(2)
+	logs[0].Fields.Equals(fmt.Sprintf("goroutine=%d", main))
+	logs[1].Fields.Equals(fmt.Sprintf("goroutine=%d", main))
+	logs[1].Message.Equals("engage")
+	t.Expect(other == main).Equals(false)
+}
+
+func TestEnabled(test *testing.T) {
+	t := preflight.Unit(test)
+
+	log := getLogger()
+	log.SetLevel(levels.Info)
+
+	t.Expect(log.Enabled(levels.Debug)).Equals(false)
+	t.Expect(log.Enabled(levels.Info)).Equals(true)
+	t.Expect(log.Enabled(levels.Error)).Equals(true)
+}
+
+func TestLogFunc(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		log.SetLevel(levels.Info)
+
+		log.LogFunc(levels.Debug, func() string {
+			t.T.Error("text should not be produced below the threshold")
+
+			return "x"
+		})
+		log.Field("scan", msg.Lazy(func() interface{} {
+			return 1701
+		})).Info("sensors")
+	})
+
+	t.Expect(stdout).HasLength(1)
+	stdout[0].Fields.Equals("scan=1701")
+}
+
+func ExampleLogger_Filters() {
+	log := captainslog.NewLogger()
+
+	// Keep the first 10 identical messages every second, then every 100th
+	log.Filters = append(log.Filters, sample.New(sample.Policy{
+		First:      10,
+		Thereafter: 100,
+		Interval:   time.Second,
+	}))
+
+	for i := 0; i < 1000; i++ {
+		log.Debug("scanning sector %d", i)
+	}
+}
+
+func TestSampling(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		log.Filters = append(log.Filters, sample.New(sample.Policy{
+			First:    2,
+			Interval: time.Second,
+		}))
+
+		for i := 0; i < 5; i++ {
+			log.Debug("scanning sector %d", i)
+		}
+	})
+
+	t.Expect(stdout).HasLength(2)
+	stdout[1].Message.Equals("scanning sector 1")
+}
+
+func TestRateLimitPerCallSite(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		limiter := limit.New(1, 1)
+		limiter.PerCallSite = true
+		log.Filters = append(log.Filters, limiter)
+
+		for i := 0; i < 3; i++ {
+			log.Info("phasers")
+			log.Info("torpedos")
+		}
+	})
+
+	t.Expect(stdout).HasLength(2)
+	stdout[0].Message.Equals("phasers")
+	stdout[1].Message.Equals("torpedos")
+}
+
+func ExampleLogger_AddHook() {
+	log := captainslog.NewLogger()
+
+	// Add a field to every warning and error
+	log.AddHook(func(m *msg.Message) bool {
+		m.Field("ship", "enterprise")
+
+		return true
+	}, levels.Warn, levels.Error)
+
+	log.Warn("shields at %d%%", 20)
+}
+
+func TestAddHook(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, stderr := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+
+		log.AddHook(func(m *msg.Message) bool {
+			m.Field("ship", "enterprise")
+
+			return true
+		})
+		log.AddHook(func(m *msg.Message) bool {
+			return m.Text != "classified"
+		}, levels.Warn)
+
+		child := log.With(msg.String("deck", "bridge"))
+		child.AddHook(func(m *msg.Message) bool {
+			m.Remove("deck")
+
+			return true
+		})
+
+		child.Info("engage")
+		child.Warn("classified")
+		log.Info("classified")
+	})
+
+	t.Expect(stdout).HasLength(2)
+	t.Expect(stderr).HasLength(0)
+	stdout[0].Fields.Equals("ship=\"enterprise\"")
+	stdout[1].Message.Equals("classified")
+}
+
+func TestSetLevel(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		child := log.With(msg.String("ship", "enterprise"))
+
+		// changes should be safe while logging
+		wg := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					log.Trace("scan %d", j)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					child.Field("deck", j).Trace("scan")
+				}
+			}()
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					log.SetLevel((i + j) % levels.Warn)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// derived loggers should see the change
+		log.SetLevel(levels.Info)
+		child.Debug("hidden")
+		child.Info("engage")
+	})
+
+	stdout[len(stdout)-1].Message.Equals("engage")
+}
+
+/**
+ * Test Helpers
+ */
+type breach struct {
+	deck int
+}
+
+func (b *breach) Error() string {
+	return fmt.Sprintf("hull breach on deck %d", b.deck)
+}
@@ -0,0 +1,88 @@
+package meta
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// Metadata identifies the process that logs messages
+type Metadata struct {
+	Hostname   string
+	PID        int
+	Executable string
+	GoVersion  string
+	// path and version of the main module, and the revision it was built
+	// from if the build recorded version control information
+	Module   string
+	Version  string
+	Revision string
+	// names of the service and of the environment it runs in, such as "production"
+	Service     string
+	Environment string
+}
+
+// metadata of the current process, which is read once
+var (
+	process Metadata
+	once    sync.Once
+)
+
+// Collect returns metadata about the current process with the names
+// of its service and environment, which may be empty
+func Collect(service string, environment string) Metadata {
+	once.Do(func() {
+		process.Hostname, _ = os.Hostname()
+		process.PID = os.Getpid()
+		if exe, err := os.Executable(); err == nil {
+			process.Executable = filepath.Base(exe)
+		}
+		process.GoVersion = runtime.Version()
+		if info, ok := debug.ReadBuildInfo(); ok {
+			process.Module = info.Main.Path
+			process.Version = info.Main.Version
+			process.Revision = revision(info)
+		}
+	})
+
+	m := process
+	m.Service = service
+	m.Environment = environment
+
+	return m
+}
+
+// Fields returns the metadata as a list of fields, leaving out the empty ones
+func (m Metadata) Fields() []msg.Field {
+	fields := make([]msg.Field, 0, 9)
+	add := func(key string, value string) {
+		if len(value) > 0 {
+			fields = append(fields, msg.String(key, value))
+		}
+	}
+
+	add("service", m.Service)
+	add("environment", m.Environment)
+	add("hostname", m.Hostname)
+	if m.PID > 0 {
+		fields = append(fields, msg.Int("pid", m.PID))
+	}
+	add("executable", m.Executable)
+	add("go", m.GoVersion)
+	add("module", m.Module)
+	add("version", m.Version)
+	add("revision", m.Revision)
+
+	return fields
+}
+
+// Group returns a field that nests the metadata under the key. Its encoding
+// is computed once, so add it to a logger with With to include it in every
+// message at little cost.
+func (m Metadata) Group(key string) msg.Field {
+	return msg.StaticGroup(key, m.Fields()...)
+}
@@ -0,0 +1,57 @@
+package meta_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/meta"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/preflight"
+)
+
+func ExampleCollect() {
+	log := captainslog.NewLogger().With(meta.Collect("api", "production").Group("process"))
+	log.Info("started")
+}
+
+func TestCollect(test *testing.T) {
+	t := preflight.Unit(test)
+
+	hostname, _ := os.Hostname()
+	m := meta.Collect("warp", "production")
+	t.Expect(m.Hostname).Equals(hostname)
+	t.Expect(m.PID).Equals(os.Getpid())
+	t.Expect(m.GoVersion).Equals(runtime.Version())
+	t.Expect(m.Service).Equals("warp")
+	t.Expect(m.Environment).Equals("production")
+
+	// service and environment should not be shared between calls
+	t.Expect(meta.Collect("", "").Service).Equals("")
+}
+
+func TestFields(test *testing.T) {
+	t := preflight.Unit(test)
+
+	m := meta.Metadata{
+		Hostname:  "enterprise",
+		PID:       1701,
+		GoVersion: "go1.17",
+		Revision:  "abc123",
+		Service:   "warp",
+	}
+
+	// empty values should be left out
+	t.Expect(m.Fields()).Equals([]msg.Field{
+		msg.String("service", "warp"),
+		msg.String("hostname", "enterprise"),
+		msg.Int("pid", 1701),
+		msg.String("go", "go1.17"),
+		msg.String("revision", "abc123"),
+	})
+
+	group := m.Group("process")
+	t.Expect(group.Key).Equals("process")
+	t.Expect(group.Group()).Equals(m.Fields())
+}
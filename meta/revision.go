@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package meta
+
+import (
+	"runtime/debug"
+)
+
+// revision returns the version control revision of a build, marked as
+// modified if the working tree had uncommitted changes
+func revision(info *debug.BuildInfo) string {
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && len(revision) > 0 {
+		return revision + "+dirty"
+	}
+
+	return revision
+}
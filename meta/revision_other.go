@@ -0,0 +1,14 @@
+//go:build !go1.18
+// +build !go1.18
+
+package meta
+
+import (
+	"runtime/debug"
+)
+
+// revision returns an empty string, since builds before Go 1.18
+// do not record version control information
+func revision(*debug.BuildInfo) string {
+	return ""
+}
@@ -1,17 +1,63 @@
 package msg
 
 import (
-	"github.com/fatih/color"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Color adds color codes to a string
 type Color func(string, ...interface{}) string
 
-// Color print functions
-var (
-	cyan   = color.New(color.FgHiCyan).SprintfFunc()
-	blue   = color.New(color.FgHiBlue).SprintfFunc()
-	green  = color.New(color.FgHiGreen).SprintfFunc()
-	yellow = color.New(color.FgHiYellow).SprintfFunc()
-	red    = color.New(color.FgHiRed).SprintfFunc()
-)
+// Reset is the escape sequence that restores the default style
+const Reset = "\x1b[0m"
+
+// Style is an escape sequence that sets the color and attributes of text;
+// the empty style leaves text as it is
+type Style string
+
+// ANSI returns a style with SGR parameters, such as 1 for bold and
+// 31 to 37 or 90 to 97 for the 16 basic colors
+func ANSI(params ...int) Style {
+	codes := make([]string, len(params))
+	for i, param := range params {
+		codes[i] = strconv.Itoa(param)
+	}
+
+	return Style("\x1b[" + strings.Join(codes, ";") + "m")
+}
+
+// Color256 returns a style with a foreground color from the 256-color palette
+func Color256(n uint8) Style {
+	return ANSI(38, 5, int(n))
+}
+
+// RGB returns a style with a 24-bit foreground color
+func RGB(r, g, b uint8) Style {
+	return ANSI(38, 2, int(r), int(g), int(b))
+}
+
+// Append appends styled text to a byte slice
+func (s Style) Append(b []byte, text string) []byte {
+	if len(s) == 0 {
+		return append(b, text...)
+	}
+	b = append(b, s...)
+	b = append(b, text...)
+
+	return append(b, Reset...)
+}
+
+// Sprintf returns styled formatted text
+func (s Style) Sprintf(format string, args ...interface{}) string {
+	return s.Sprint(fmt.Sprintf(format, args...))
+}
+
+// Sprint returns styled text
+func (s Style) Sprint(args ...interface{}) string {
+	if len(s) == 0 {
+		return fmt.Sprint(args...)
+	}
+
+	return string(s) + fmt.Sprint(args...) + Reset
+}
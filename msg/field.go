@@ -0,0 +1,244 @@
+package msg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Kind identifies the type of value held by a field
+type Kind uint8
+
+// Field kinds
+const (
+	AnyKind Kind = iota
+	StringKind
+	IntKind
+	FloatKind
+	BoolKind
+	DurationKind
+	TimeKind
+	ErrorKind
+	// group of fields nested under the key
+	GroupKind
+	// marker after which all fields are nested under the key
+	OpenGroupKind
+	// value that is computed when the message is logged
+	LazyKind
+)
+
+// Lazy is a function that computes the value of a field only if
+// the message is logged. It can also be passed as an argument to
+// a format string.
+type Lazy func() interface{}
+
+// Field is a key-value pair. Values of common types are stored without
+// boxing them in an interface; use the constructors to create fields.
+type Field struct {
+	Key  string
+	Kind Kind
+	// integer value or bits of a float, bool, duration, or time
+	Num int64
+	// string value
+	Str string
+	// error, arbitrary value, nested fields, or location of a time
+	Any interface{}
+}
+
+// String returns a field with a string value
+func String(key string, value string) Field {
+	return Field{Key: key, Kind: StringKind, Str: value}
+}
+
+// Int returns a field with an int value
+func Int(key string, value int) Field {
+	return Field{Key: key, Kind: IntKind, Num: int64(value)}
+}
+
+// Int64 returns a field with an int64 value
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Kind: IntKind, Num: value}
+}
+
+// Float64 returns a field with a float64 value
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Kind: FloatKind, Num: int64(math.Float64bits(value))}
+}
+
+// Bool returns a field with a bool value
+func Bool(key string, value bool) Field {
+	var n int64
+	if value {
+		n = 1
+	}
+
+	return Field{Key: key, Kind: BoolKind, Num: n}
+}
+
+// Duration returns a field with a time.Duration value
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Kind: DurationKind, Num: int64(value)}
+}
+
+// Time returns a field with a time.Time value
+func Time(key string, value time.Time) Field {
+	// times outside the range of UnixNano are stored as they are
+	if value.Year() < 1678 || value.Year() > 2261 {
+		return Field{Key: key, Kind: TimeKind, Any: value}
+	}
+
+	return Field{Key: key, Kind: TimeKind, Num: value.UnixNano(), Any: value.Location()}
+}
+
+// Err returns a field with an error value
+func Err(key string, value error) Field {
+	return Field{Key: key, Kind: ErrorKind, Any: value}
+}
+
+// Group returns a field that nests other fields under the key
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Kind: GroupKind, Any: fields}
+}
+
+// OpenGroup returns a marker that nests all the fields after it under the key
+func OpenGroup(key string) Field {
+	return Field{Key: key, Kind: OpenGroupKind}
+}
+
+// Any returns a field with a value of any type, which is stored
+// without boxing it if possible
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return Err(key, v)
+	case Lazy:
+		return Field{Key: key, Kind: LazyKind, Any: v}
+	case func() interface{}:
+		return Field{Key: key, Kind: LazyKind, Any: Lazy(v)}
+	default:
+		return Field{Key: key, Kind: AnyKind, Any: value}
+	}
+}
+
+// Format implements fmt.Formatter by formatting the computed value
+func (lazy Lazy) Format(state fmt.State, verb rune) {
+	format := "%"
+	for _, flag := range "+-# 0" {
+		if state.Flag(int(flag)) {
+			format += string(flag)
+		}
+	}
+	if width, ok := state.Width(); ok {
+		format += strconv.Itoa(width)
+	}
+	if precision, ok := state.Precision(); ok {
+		format += "." + strconv.Itoa(precision)
+	}
+
+	fmt.Fprintf(state, format+string(verb), lazy())
+}
+
+// resolve computes the values of lazy fields in a list, copying
+// any nested groups that contain them
+func resolve(fields []Field) {
+	for i, field := range fields {
+		switch field.Kind {
+		case LazyKind:
+			fields[i] = Any(field.Key, field.Any.(Lazy)())
+		case GroupKind:
+			if isLazy(field.Group()) {
+				nested := append([]Field{}, field.Group()...)
+				resolve(nested)
+				fields[i].Any = nested
+			}
+		}
+	}
+}
+
+// isLazy returns true if a list of fields has any lazy values, including nested ones
+func isLazy(fields []Field) bool {
+	for _, field := range fields {
+		if field.Kind == LazyKind || (field.Kind == GroupKind && isLazy(field.Group())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Float returns the value of a field with kind FloatKind
+func (f Field) Float() float64 {
+	return math.Float64frombits(uint64(f.Num))
+}
+
+// Bool returns the value of a field with kind BoolKind
+func (f Field) Bool() bool {
+	return f.Num != 0
+}
+
+// Duration returns the value of a field with kind DurationKind
+func (f Field) Duration() time.Duration {
+	return time.Duration(f.Num)
+}
+
+// Time returns the value of a field with kind TimeKind
+func (f Field) Time() time.Time {
+	if loc, ok := f.Any.(*time.Location); ok {
+		return time.Unix(0, f.Num).In(loc)
+	}
+	t, _ := f.Any.(time.Time)
+
+	return t
+}
+
+// Err returns the value of a field with kind ErrorKind
+func (f Field) Err() error {
+	err, _ := f.Any.(error)
+
+	return err
+}
+
+// Group returns the nested fields of a field with kind GroupKind
+func (f Field) Group() []Field {
+	if static, ok := f.Any.(*Static); ok {
+		return static.Fields()
+	}
+	fields, _ := f.Any.([]Field)
+
+	return fields
+}
+
+// Value returns the value of a field as an interface
+func (f Field) Value() interface{} {
+	switch f.Kind {
+	case StringKind:
+		return f.Str
+	case IntKind:
+		return f.Num
+	case FloatKind:
+		return f.Float()
+	case BoolKind:
+		return f.Bool()
+	case DurationKind:
+		return f.Duration()
+	case TimeKind:
+		return f.Time()
+	default:
+		return f.Any
+	}
+}
@@ -0,0 +1,77 @@
+package msg_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/preflight"
+)
+
+func TestFieldKinds(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stardate := time.Date(2364, 1, 2, 3, 4, 5, 6, time.UTC)
+	err := errors.New("shields down")
+
+	t.Expect(msg.String("captain", "picard").Kind).Equals(msg.StringKind)
+	t.Expect(msg.Int("decks", 42).Kind).Equals(msg.IntKind)
+	t.Expect(msg.Int64("crew", 1012).Kind).Equals(msg.IntKind)
+	t.Expect(msg.Float64("warp", 9.5).Kind).Equals(msg.FloatKind)
+	t.Expect(msg.Bool("cloaked", true).Kind).Equals(msg.BoolKind)
+	t.Expect(msg.Duration("eta", time.Minute).Kind).Equals(msg.DurationKind)
+	t.Expect(msg.Time("stardate", stardate).Kind).Equals(msg.TimeKind)
+	t.Expect(msg.Err("error", err).Kind).Equals(msg.ErrorKind)
+	t.Expect(msg.Any("ship", struct{}{}).Kind).Equals(msg.AnyKind)
+}
+
+func TestFieldValues(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stardate := time.Date(2364, 1, 2, 3, 4, 5, 6, time.UTC)
+	ancient := time.Date(1066, 10, 14, 0, 0, 0, 0, time.UTC)
+	err := errors.New("shields down")
+
+	t.Expect(msg.String("captain", "picard").Value()).Equals("picard")
+	t.Expect(msg.Int("decks", 42).Value()).Equals(int64(42))
+	t.Expect(msg.Float64("warp", 9.5).Value()).Equals(9.5)
+	t.Expect(msg.Bool("cloaked", true).Value()).Equals(true)
+	t.Expect(msg.Duration("eta", time.Minute).Value()).Equals(time.Minute)
+	t.Expect(msg.Time("stardate", stardate).Time().Equal(stardate)).Equals(true)
+	t.Expect(msg.Time("hastings", ancient).Time().Equal(ancient)).Equals(true)
+	t.Expect(msg.Err("error", err).Value()).Equals(err)
+}
+
+func TestAny(test *testing.T) {
+	t := preflight.Unit(test)
+
+	// values of common types should not be boxed
+	t.Expect(msg.Any("captain", "picard")).Equals(msg.String("captain", "picard"))
+	t.Expect(msg.Any("decks", 42)).Equals(msg.Int("decks", 42))
+	t.Expect(msg.Any("warp", 9.5)).Equals(msg.Float64("warp", 9.5))
+	t.Expect(msg.Any("eta", time.Minute)).Equals(msg.Duration("eta", time.Minute))
+	t.Expect(msg.Any("decks", uint8(42)).Value()).Equals(uint8(42))
+}
+
+func TestStaticGroup(test *testing.T) {
+	t := preflight.Unit(test)
+
+	field := msg.StaticGroup("ship", msg.String("name", "enterprise"))
+	t.Expect(field.Kind).Equals(msg.GroupKind)
+	t.Expect(field.Group()).Equals([]msg.Field{msg.String("name", "enterprise")})
+
+	// encodings should be computed once for each format
+	calls := 0
+	encode := func(b []byte, fields []msg.Field) []byte {
+		calls++
+
+		return append(b, fields[0].Str...)
+	}
+	static := field.Any.(*msg.Static)
+	t.Expect(string(static.Encoding("text", encode))).Equals("enterprise")
+	t.Expect(string(static.Encoding("text", encode))).Equals("enterprise")
+	t.Expect(calls).Equals(1)
+	static.Encoding("json", encode)
+	t.Expect(calls).Equals(2)
+}
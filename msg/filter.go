@@ -0,0 +1,53 @@
+package msg
+
+import (
+	"vincent.click/pkg/captainslog/v2/goroutine"
+)
+
+// Filter decides whether a message that passed the level check gets printed
+type Filter interface {
+	Allow(msg *Message) bool
+}
+
+// SiteFilter is a filter that needs to know the call site of each message
+type SiteFilter interface {
+	Filter
+	// NeedsSite returns true if the call site should be recorded in Message.PC
+	NeedsSite() bool
+}
+
+// goroutineFilter allows only messages from a set of goroutines
+type goroutineFilter map[uint64]struct{}
+
+// Goroutines returns a filter that allows only messages logged by the goroutines
+// with the given IDs, to follow them through interleaved logs
+func Goroutines(ids ...uint64) Filter {
+	filter := make(goroutineFilter, len(ids))
+	for _, id := range ids {
+		filter[id] = struct{}{}
+	}
+
+	return filter
+}
+
+// Allow returns true if the message was logged by one of the goroutines
+func (f goroutineFilter) Allow(msg *Message) bool {
+	id := msg.Goroutine
+	if id == 0 {
+		id = goroutine.ID()
+	}
+	_, ok := f[id]
+
+	return ok
+}
+
+// allowed returns true if every filter allows the message
+func (msg *Message) allowed() bool {
+	for _, filter := range msg.Filters {
+		if !filter.Allow(msg) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+package msg
+
+// Hook inspects a message after the level check and before it is printed.
+// It can change the message's level and fields, or return false to drop it.
+type Hook func(msg *Message) bool
+
+// OnLevels returns a hook that only runs for messages with one of the given levels
+func OnLevels(hook Hook, levels ...int) Hook {
+	return func(msg *Message) bool {
+		for _, level := range levels {
+			if msg.Level == level {
+				return hook(msg)
+			}
+		}
+
+		return true
+	}
+}
+
+// hooked runs the hooks in order and returns true if the message
+// should still be printed
+func (msg *Message) hooked() bool {
+	for _, hook := range msg.Hooks {
+		if !hook(msg) {
+			return false
+		}
+	}
+
+	// hooks might have lowered the level
+	return msg.Level >= msg.Threshold
+}
+
+// Remove removes the top-level fields with a key from the message
+func (msg *Message) Remove(key string) *Message {
+	kept := msg.Data[:0]
+	for _, field := range msg.Data {
+		if field.Key != key || field.Kind == OpenGroupKind {
+			kept = append(kept, field)
+		}
+	}
+	for i := len(kept); i < len(msg.Data); i++ {
+		msg.Data[i] = Field{}
+	}
+	msg.Data = kept
+
+	return msg
+}
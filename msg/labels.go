@@ -0,0 +1,157 @@
+package msg
+
+import (
+	"unicode/utf8"
+
+	"vincent.click/pkg/captainslog/v2/levels"
+)
+
+// Labels are the names of levels in text logs
+type Labels struct {
+	Trace string
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+	Fatal string
+	// labels of other levels, such as custom ones; levels without a label
+	// use their name or number from the levels package
+	Custom map[int]string
+	// text around every label, such as brackets
+	Prefix string
+	Suffix string
+	// width that labels are padded to on the left, or on the right if
+	// negative; 0 leaves them unpadded
+	Width int
+}
+
+// Built-in labels
+var (
+	// Lowercase labels, as in "info", padded to 6 characters
+	Lowercase = &Labels{
+		Trace: "trace",
+		Debug: "debug",
+		Info:  "info",
+		Warn:  "warn",
+		Error: "error",
+		Fatal: "fatal",
+		Width: 6,
+	}
+	// Uppercase labels, as in "INFO"
+	Uppercase = &Labels{
+		Trace: "TRACE",
+		Debug: "DEBUG",
+		Info:  "INFO",
+		Warn:  "WARN",
+		Error: "ERROR",
+		Fatal: "FATAL",
+		Width: 5,
+	}
+	// Short labels of three letters, as in "INF"
+	Short = &Labels{
+		Trace: "TRC",
+		Debug: "DBG",
+		Info:  "INF",
+		Warn:  "WRN",
+		Error: "ERR",
+		Fatal: "FTL",
+		Width: 3,
+	}
+	// Letters labels each level with a single letter, as in "I"
+	Letters = &Labels{
+		Trace: "T",
+		Debug: "D",
+		Info:  "I",
+		Warn:  "W",
+		Error: "E",
+		Fatal: "F",
+		Width: 1,
+	}
+	// DefaultLabels are used by messages without labels
+	DefaultLabels = Lowercase
+)
+
+// Bracketed returns a copy of labels wrapped in brackets, as in "[WARN]"
+func Bracketed(labels *Labels) *Labels {
+	copied := *labels
+	copied.Prefix = "[" + labels.Prefix
+	copied.Suffix = labels.Suffix + "]"
+	switch {
+	case labels.Width > 0:
+		copied.Width += 2
+	case labels.Width < 0:
+		copied.Width -= 2
+	}
+
+	return &copied
+}
+
+// Unpadded returns a copy of labels that are not padded
+func Unpadded(labels *Labels) *Labels {
+	copied := *labels
+	copied.Width = 0
+
+	return &copied
+}
+
+// Name returns the label of a level without its prefix, suffix, or padding
+func (l *Labels) Name(level int) string {
+	var name string
+	switch level {
+	case levels.Trace:
+		name = l.Trace
+	case levels.Debug:
+		name = l.Debug
+	case levels.Info:
+		name = l.Info
+	case levels.Warn:
+		name = l.Warn
+	case levels.Error:
+		name = l.Error
+	case levels.Fatal:
+		name = l.Fatal
+	default:
+		name = l.Custom[level]
+	}
+	if len(name) == 0 {
+		return levels.Name(level)
+	}
+
+	return name
+}
+
+// Label returns the padded label of a level
+func (l *Labels) Label(level int) string {
+	return string(l.Append(nil, level))
+}
+
+// Append appends the padded label of a level
+func (l *Labels) Append(b []byte, level int) []byte {
+	name := l.Name(level)
+	padding := l.Width
+	if padding < 0 {
+		padding = -padding
+	}
+	padding -= utf8.RuneCountInString(l.Prefix) + utf8.RuneCountInString(name) + utf8.RuneCountInString(l.Suffix)
+
+	if l.Width > 0 {
+		b = appendSpaces(b, padding)
+	}
+	b = append(b, l.Prefix...)
+	b = append(b, name...)
+	b = append(b, l.Suffix...)
+	if l.Width < 0 {
+		b = appendSpaces(b, padding)
+	}
+
+	return b
+}
+
+// appendSpaces appends n spaces
+func appendSpaces(b []byte, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, ' ')
+	}
+
+	return b
+}
@@ -3,32 +3,58 @@ package msg
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
+	"vincent.click/pkg/captainslog/v2/goroutine"
 	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/tty"
 	"vincent.click/pkg/preflight"
 )
 
-// Field is a key-value pair
-type Field [2]interface{}
-
 // Format formats and prints out a log message
 type Format func(msg *Message)
 
 // Message is a log message that gets built in multiple steps
 type Message struct {
-	Time      string
-	Name      string
-	Text      string
+	Time time.Time
+	// layout used by text formats to print the time
+	TimeFormat string
+	Name       string
+	Text       string
+	// format string of the text
+	Template  string
 	Level     int
 	Threshold int
-	HasColor  bool
-	Stdout    *os.File
-	Stderr    *os.File
-	Print     Format
-	Data      []interface{}
+	// whether colors may be used, if the stream supports them
+	HasColor bool
+	// whether colors are used even if the stream does not support them
+	ForceColor bool
+	// styles of colored logs; the default theme is used if empty
+	Theme *Theme
+	// names of the levels in text formats; the default labels are used if empty
+	Labels *Labels
+	Stdout *os.File
+	Stderr *os.File
+	Print  Format
+	Data   []Field
+	// hooks that run before the filters, in order
+	Hooks []Hook
+	// filters that decide whether the message is printed
+	Filters []Filter
+	// program counter of the call site, if a filter needs it
+	PC uintptr
+	// whether to record the ID of the goroutine that logs the message
+	// and add it as the first field
+	RecordGoroutine bool
+	// ID of the goroutine that logged the message, or 0 if it was not recorded
+	Goroutine uint64
 }
 
+// GoroutineKey is the key of the field with the ID of the goroutine that logged a message
+const GoroutineKey = "goroutine"
+
 // MsgPool is a synchronized pool of messages
 var MsgPool = sync.Pool{
 	New: func() interface{} {
@@ -36,36 +62,90 @@ var MsgPool = sync.Pool{
 	},
 }
 
-// Props returns the message stream, level, and color
+// Props returns the message stream, level, and color, which adds
+// no color codes if the message is not colored
 func (msg *Message) Props() (stream *os.File, level string, color Color) {
+	stream, level = msg.props()
+	if !msg.Colored() {
+		return stream, level, fmt.Sprintf
+	}
+
+	return stream, level, msg.Style().Sprintf
+}
+
+// props returns the message stream and level
+func (msg *Message) props() (stream *os.File, level string) {
 	switch msg.Level {
 	case levels.Trace:
-		return msg.Stdout, "trace", cyan
+		return msg.Stdout, "trace"
 	case levels.Debug:
-		return msg.Stdout, "debug", green
+		return msg.Stdout, "debug"
 	case levels.Info:
-		return msg.Stdout, "info", blue
+		return msg.Stdout, "info"
 	case levels.Warn:
-		return msg.Stderr, "warn", yellow
+		return msg.Stderr, "warn"
 	case levels.Error:
-		return msg.Stderr, "error", red
+		return msg.Stderr, "error"
 	default:
-		return msg.Stderr, "fatal", red
+		return msg.Stderr, "fatal"
+	}
+}
+
+// Colored returns true if the message is printed with colors on its stream
+func (msg *Message) Colored() bool {
+	if !msg.HasColor {
+		return false
+	}
+	stream, _ := msg.props()
+
+	return msg.ForceColor || tty.Color(stream)
+}
+
+// Colors returns the theme of the message
+func (msg *Message) Colors() *Theme {
+	if msg.Theme == nil {
+		return DefaultTheme
 	}
+
+	return msg.Theme
+}
+
+// Style returns the style of the message level
+func (msg *Message) Style() Style {
+	return msg.Colors().Level(msg.Level)
+}
+
+// LevelLabels returns the labels of the message levels
+func (msg *Message) LevelLabels() *Labels {
+	if msg.Labels == nil {
+		return DefaultLabels
+	}
+
+	return msg.Labels
+}
+
+// Label returns the padded label of the message level, for text formats
+func (msg *Message) Label() string {
+	return msg.LevelLabels().Label(msg.Level)
 }
 
 // Field adds a data field to the message
 func (msg *Message) Field(name string, value interface{}) *Message {
-	msg.Data = append(msg.Data, name, value)
+	msg.Data = append(msg.Data, Any(name, value))
 
 	return msg
 }
 
 // Fields adds multiple fields to the message
 func (msg *Message) Fields(fields ...Field) *Message {
-	for _, field := range fields {
-		msg.Data = append(msg.Data, field[0], field[1])
-	}
+	msg.Data = append(msg.Data, fields...)
+
+	return msg
+}
+
+// Group adds fields to the message nested under a name
+func (msg *Message) Group(name string, fields ...Field) *Message {
+	msg.Data = append(msg.Data, Group(name, fields...))
 
 	return msg
 }
@@ -77,12 +157,57 @@ func (msg *Message) Log(level int, format string, args ...interface{}) {
 		return
 	}
 
-	msg.Text = fmt.Sprintf(format, args...)
-	msg.Print(msg)
-	// Return message to pool
+	// text without verbs is used as is, which avoids an allocation
+	if len(args) == 0 && strings.IndexByte(format, '%') < 0 {
+		msg.Text = format
+	} else {
+		msg.Text = fmt.Sprintf(format, args...)
+	}
+	msg.Template = format
+	msg.output()
+}
+
+// LogFunc outputs the message with the specified level, calling
+// a function to produce the text only if the message is logged
+func (msg *Message) LogFunc(level int, text func() string) {
+	msg.Level = level
+	if msg.Level < msg.Threshold {
+		return
+	}
+
+	msg.Text = text()
+	msg.Template = msg.Text
+	msg.output()
+}
+
+// output evaluates lazy fields, runs the hooks, prints the message
+// if the filters allow it, and returns it to the pool
+func (msg *Message) output() {
+	msg.recordGoroutine()
+	resolve(msg.Data)
+	if msg.hooked() && msg.allowed() {
+		msg.Print(msg)
+	}
+	// Return message to pool without holding on to field values
+	for i := range msg.Data {
+		msg.Data[i] = Field{}
+	}
 	MsgPool.Put(msg)
 }
 
+// recordGoroutine sets the ID of the current goroutine and adds it as the
+// first field, if the message records it
+func (msg *Message) recordGoroutine() {
+	msg.Goroutine = 0
+	if !msg.RecordGoroutine {
+		return
+	}
+	msg.Goroutine = goroutine.ID()
+	msg.Data = append(msg.Data, Field{})
+	copy(msg.Data[1:], msg.Data)
+	msg.Data[0] = Int64(GoroutineKey, int64(msg.Goroutine))
+}
+
 // Trace outputs the message with level Trace
 func (msg *Message) Trace(format string, args ...interface{}) {
 	msg.Log(levels.Trace, format, args...)
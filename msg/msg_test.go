@@ -3,8 +3,10 @@ package msg_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/goroutine"
 	"vincent.click/pkg/captainslog/v2/levels"
 	"vincent.click/pkg/captainslog/v2/msg"
 	"vincent.click/pkg/preflight"
@@ -52,6 +54,30 @@ func TestLogs(test *testing.T) {
 	message.Error("captainslog")
 }
 
+func TestLogFormat(test *testing.T) {
+	t := preflight.Unit(test)
+
+	texts := []string{}
+	for _, args := range [][]interface{}{nil, {9}} {
+		message := createMessage(levels.Info)
+		message.Print = func(input *msg.Message) {
+			texts = append(texts, input.Text)
+		}
+		message.Info("warp 100%% of %d", args...)
+	}
+
+	// the text should always be formatted, even without arguments
+	t.Expect(texts).Equals([]string{"warp 100% of %!d(MISSING)", "warp 100% of 9"})
+
+	// text without verbs should not be copied
+	message := createMessage(levels.Info)
+	message.Print = func(*msg.Message) {}
+	allocs := testing.AllocsPerRun(100, func() {
+		message.Info("engage")
+	})
+	t.Expect(allocs).Equals(0.0)
+}
+
 func TestExit(test *testing.T) {
 	t := preflight.Unit(test)
 
@@ -89,7 +115,8 @@ func TestField(test *testing.T) {
 	message := createMessage(levels.Info)
 	message.Field("science officer", "data")
 
-	t.Expect(message.Data).HasLength(2)
+	t.Expect(message.Data).HasLength(1)
+	t.Expect(message.Data[0]).Equals(msg.String("science officer", "data"))
 }
 
 func TestFields(test *testing.T) {
@@ -97,25 +124,293 @@ func TestFields(test *testing.T) {
 
 	message := createMessage(levels.Info)
 	message.Fields(
-		msg.Field{"science officer", "data"},
-		msg.Field{"chief engineer", "geordi la forge"},
+		msg.String("science officer", "data"),
+		msg.String("chief engineer", "geordi la forge"),
 	)
 
-	t.Expect(message.Data).HasLength(4)
+	t.Expect(message.Data).HasLength(2)
+}
+
+func TestGroup(test *testing.T) {
+	t := preflight.Unit(test)
+
+	message := createMessage(levels.Info)
+	message.Group("http",
+		msg.String("method", "GET"),
+		msg.Int("status", 200),
+	)
+
+	t.Expect(message.Data).HasLength(1)
+	t.Expect(message.Data[0].Kind).Equals(msg.GroupKind)
+	t.Expect(message.Data[0].Group()).HasLength(2)
+}
+
+func TestLazy(test *testing.T) {
+	t := preflight.Unit(test)
+
+	calls := 0
+	scan := msg.Lazy(func() interface{} {
+		calls++
+
+		return "all decks clear"
+	})
+
+	message := createMessage(levels.Info)
+	message.Threshold = levels.Info
+	message.Field("scan", scan).Debug("sensors")
+
+	// lazy values should not be computed if the message is not logged
+	t.Expect(calls).Equals(0)
+
+	message = createMessage(levels.Info)
+	message.Print = func(input *msg.Message) {
+		t.Expect(input.Data[0]).Equals(msg.String("scan", "all decks clear"))
+		t.Expect(input.Data[1].Group()[0]).Equals(msg.String("scan", "all decks clear"))
+	}
+	message.Field("scan", scan).Group("sensors", msg.Any("scan", scan)).Info("sensors")
+
+	t.Expect(calls).Equals(2)
+}
+
+func TestLazyFormat(test *testing.T) {
+	t := preflight.Unit(test)
+
+	warp := msg.Lazy(func() interface{} {
+		return 9.5
+	})
+
+	message := createMessage(levels.Info)
+	message.Print = func(input *msg.Message) {
+		t.Expect(input.Text).Equals("warp  9.50")
+	}
+	message.Info("warp %5.2f", warp)
+}
+
+func TestLogFunc(test *testing.T) {
+	t := preflight.Unit(test)
+
+	calls := 0
+	text := func() string {
+		calls++
+
+		return "captainslog"
+	}
+
+	message := createMessage(levels.Info)
+	message.Threshold = levels.Info
+	message.LogFunc(levels.Debug, text)
+
+	t.Expect(calls).Equals(0)
+
+	message = createMessage(levels.Info)
+	message.Print = func(input *msg.Message) {
+		t.Expect(input.Text).Equals("captainslog")
+	}
+	message.LogFunc(levels.Info, text)
+
+	t.Expect(calls).Equals(1)
+}
+
+func TestFilters(test *testing.T) {
+	t := preflight.Unit(test)
+
+	printed := 0
+	message := createMessage(levels.Info)
+	message.Print = func(input *msg.Message) {
+		printed++
+	}
+	message.Filters = []msg.Filter{veto{}}
+	message.Info("captainslog")
+
+	t.Expect(printed).Equals(0)
+}
+
+func TestHooks(test *testing.T) {
+	t := preflight.Unit(test)
+
+	order := []string{}
+	message := createMessage(levels.Info)
+	message.Hooks = []msg.Hook{
+		func(input *msg.Message) bool {
+			order = append(order, "first")
+			input.Field("ship", "enterprise")
+
+			return true
+		},
+		func(input *msg.Message) bool {
+			order = append(order, "second")
+			input.Level = levels.Warn
+
+			return true
+		},
+	}
+	message.Print = func(input *msg.Message) {
+		t.Expect(input.Level).Equals(levels.Warn)
+		t.Expect(input.Data).Equals([]msg.Field{msg.String("ship", "enterprise")})
+	}
+	message.Info("captainslog")
+
+	t.Expect(order).Equals([]string{"first", "second"})
+}
+
+func TestHooksDrop(test *testing.T) {
+	t := preflight.Unit(test)
+
+	printed := 0
+	count := func(*msg.Message) {
+		printed++
+	}
+
+	message := createMessage(levels.Info)
+	message.Print = count
+	message.Hooks = []msg.Hook{func(*msg.Message) bool {
+		return false
+	}}
+	message.Info("captainslog")
+
+	// lowering the level below the threshold should drop the message
+	message = createMessage(levels.Info)
+	message.Print = count
+	message.Threshold = levels.Info
+	message.Hooks = []msg.Hook{func(input *msg.Message) bool {
+		input.Level = levels.Debug
+
+		return true
+	}}
+	message.Info("captainslog")
+
+	t.Expect(printed).Equals(0)
+}
+
+func TestOnLevels(test *testing.T) {
+	t := preflight.Unit(test)
+
+	calls := 0
+	hook := msg.OnLevels(func(*msg.Message) bool {
+		calls++
+
+		return false
+	}, levels.Warn, levels.Error)
+
+	t.Expect(hook(createMessage(levels.Info))).Equals(true)
+	t.Expect(hook(createMessage(levels.Warn))).Equals(false)
+	t.Expect(hook(createMessage(levels.Error))).Equals(false)
+	t.Expect(calls).Equals(2)
+}
+
+func TestRemove(test *testing.T) {
+	t := preflight.Unit(test)
+
+	message := createMessage(levels.Info)
+	message.Fields(
+		msg.String("captain", "picard"),
+		msg.String("token", "secret"),
+		msg.String("first officer", "riker"),
+	).Remove("token")
+
+	t.Expect(message.Data).Equals([]msg.Field{
+		msg.String("captain", "picard"),
+		msg.String("first officer", "riker"),
+	})
+}
+
+func TestGoroutines(test *testing.T) {
+	t := preflight.Unit(test)
+
+	var printed []msg.Field
+	message := createMessage(levels.Info)
+	message.Print = func(input *msg.Message) {
+		printed = append(printed, input.Data...)
+	}
+	message.RecordGoroutine = true
+	message.Field("ship", "enterprise").Info("engage")
+
+	id := goroutine.ID()
+	t.Expect(message.Goroutine).Equals(id)
+	t.Expect(printed).Equals([]msg.Field{
+		msg.Int64(msg.GoroutineKey, int64(id)),
+		msg.String("ship", "enterprise"),
+	})
+
+	// the filter should find the goroutine of messages that do not record it
+	filter := msg.Goroutines(id)
+	t.Expect(filter.Allow(createMessage(levels.Info))).Equals(true)
+	t.Expect(msg.Goroutines(id + 1).Allow(createMessage(levels.Info))).Equals(false)
+}
+
+func TestLabels(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.Expect(msg.Lowercase.Label(levels.Info)).Equals("  info")
+	t.Expect(msg.Uppercase.Label(levels.Warn)).Equals(" WARN")
+	t.Expect(msg.Short.Label(levels.Error)).Equals("ERR")
+	t.Expect(msg.Letters.Label(levels.Debug)).Equals("D")
+	t.Expect(msg.Bracketed(msg.Uppercase).Label(levels.Info)).Equals(" [INFO]")
+	t.Expect(msg.Unpadded(msg.Lowercase).Label(levels.Info)).Equals("info")
+
+	// negative widths should pad on the right
+	labels := msg.Bracketed(msg.Short)
+	labels.Width = -6
+	t.Expect(labels.Label(levels.Warn)).Equals("[WRN] ")
+
+	// custom levels should use their labels, or their names and numbers
+	labels.Custom = map[int]string{7: "AUD"}
+	t.Expect(labels.Label(7)).Equals("[AUD] ")
+	t.Expect(labels.Label(levels.Quiet)).Equals("[quiet]")
+	t.Expect(labels.Label(9)).Equals("[9]   ")
+
+	// messages should use the default labels unless they have their own
+	message := createMessage(levels.Warn)
+	t.Expect(message.Label()).Equals("  warn")
+	message.Labels = msg.Short
+	t.Expect(message.Label()).Equals("WRN")
 }
 
 /**
  * Test Helpers
  */
+type veto struct{}
+
+func (veto) Allow(*msg.Message) bool {
+	return false
+}
+
 func createMessage(level int) *msg.Message {
 	return &msg.Message{
-		Time:      "07-23-1996 07:23:00 PST",
+		Time:      time.Date(1996, 7, 23, 7, 23, 0, 0, time.UTC),
 		Name:      "captainslog",
 		Level:     level,
 		Threshold: levels.Trace,
 		Stdout:    os.Stdout,
 		Stderr:    os.Stderr,
 		Print:     format.Flat,
-		Data:      []interface{}{},
+		Data:      []msg.Field{},
 	}
 }
+
+func TestTheme(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.Expect(msg.ANSI(1, 31)).Equals(msg.Style("\x1b[1;31m"))
+	t.Expect(msg.Color256(208)).Equals(msg.Style("\x1b[38;5;208m"))
+	t.Expect(msg.RGB(0, 71, 171)).Equals(msg.Style("\x1b[38;2;0;71;171m"))
+	t.Expect(msg.ANSI(32).Sprintf("%d", 5)).Equals("\x1b[32m5\x1b[0m")
+	t.Expect(msg.Style("").Sprint("plain")).Equals("plain")
+	t.Expect(string(msg.ANSI(32).Append([]byte("> "), "ok"))).Equals("> \x1b[32mok\x1b[0m")
+
+	// should use the style of the message level
+	message := createMessage(levels.Error)
+	t.Expect(message.Colors()).Equals(msg.DefaultTheme)
+	message.Theme = msg.HighContrast
+	t.Expect(message.Style()).Equals(msg.HighContrast.Error)
+	t.Expect(msg.Light.Level(levels.Fatal)).Equals(msg.Light.Fatal)
+
+	// colors should only be added to colored messages
+	message.HasColor = true
+	message.ForceColor = true
+	_, _, color := message.Props()
+	t.Expect(color("%s", "breach")).Equals(msg.HighContrast.Error.Sprint("breach"))
+	message.HasColor = false
+	_, _, color = message.Props()
+	t.Expect(color("%s", "breach")).Equals("breach")
+}
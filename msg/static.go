@@ -0,0 +1,34 @@
+package msg
+
+import (
+	"sync"
+)
+
+// Static is a group of fields that never change, such as metadata about the
+// process, whose encodings can be computed once and reused by formats
+type Static struct {
+	fields []Field
+	// encodings by the name of the format
+	encodings sync.Map
+}
+
+// StaticGroup returns a field that nests fields that never change under the key
+func StaticGroup(key string, fields ...Field) Field {
+	return Field{Key: key, Kind: GroupKind, Any: &Static{fields: fields}}
+}
+
+// Fields returns the fields of the group
+func (s *Static) Fields() []Field {
+	return s.fields
+}
+
+// Encoding returns the fields encoded by a format, calling encode only
+// the first time that the format encodes them
+func (s *Static) Encoding(format string, encode func(b []byte, fields []Field) []byte) []byte {
+	if encoded, ok := s.encodings.Load(format); ok {
+		return encoded.([]byte)
+	}
+	encoded, _ := s.encodings.LoadOrStore(format, encode(nil, s.fields))
+
+	return encoded.([]byte)
+}
@@ -0,0 +1,83 @@
+package msg
+
+import (
+	"vincent.click/pkg/captainslog/v2/levels"
+)
+
+// Theme is a set of styles for the parts of a colored log
+type Theme struct {
+	// styles of the levels, which also apply to the name
+	Trace Style
+	Debug Style
+	Info  Style
+	Warn  Style
+	Error Style
+	Fatal Style
+	// styles of the timestamp, field keys, field values, and separators;
+	// pretty logs color values by their type if Value is empty
+	Time      Style
+	Key       Style
+	Value     Style
+	Separator Style
+}
+
+// Built-in themes
+var (
+	// Dark is a theme for terminals with dark backgrounds
+	Dark = &Theme{
+		Trace:     ANSI(96),
+		Debug:     ANSI(92),
+		Info:      ANSI(94),
+		Warn:      ANSI(93),
+		Error:     ANSI(91),
+		Fatal:     ANSI(1, 91),
+		Time:      ANSI(90),
+		Key:       ANSI(95),
+		Separator: ANSI(90),
+	}
+	// Light is a theme for terminals with light backgrounds
+	Light = &Theme{
+		Trace:     ANSI(36),
+		Debug:     ANSI(32),
+		Info:      ANSI(34),
+		Warn:      Color256(130),
+		Error:     ANSI(31),
+		Fatal:     ANSI(1, 31),
+		Time:      Color256(244),
+		Key:       ANSI(35),
+		Separator: Color256(244),
+	}
+	// HighContrast is a theme with bold colors and highlighted warnings and errors
+	HighContrast = &Theme{
+		Trace:     ANSI(1, 96),
+		Debug:     ANSI(1, 92),
+		Info:      ANSI(1, 97),
+		Warn:      ANSI(1, 30, 103),
+		Error:     ANSI(1, 97, 41),
+		Fatal:     ANSI(1, 97, 41),
+		Time:      ANSI(97),
+		Key:       ANSI(1, 97),
+		Value:     ANSI(97),
+		Separator: ANSI(97),
+	}
+	// DefaultTheme is used by messages without a theme
+	DefaultTheme = Dark
+)
+
+// Level returns the style of a level
+func (t *Theme) Level(level int) Style {
+	switch level {
+	case levels.Trace:
+		return t.Trace
+	case levels.Debug:
+		return t.Debug
+	case levels.Info:
+		return t.Info
+	case levels.Warn:
+		return t.Warn
+	case levels.Error:
+		return t.Error
+	default:
+		return t.Fatal
+	}
+}
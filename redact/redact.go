@@ -0,0 +1,454 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/values"
+)
+
+// Style is a way of masking sensitive values
+type Style int
+
+// Mask styles
+const (
+	// Full replaces the whole value
+	Full Style = iota
+	// Partial keeps the last four characters of long values
+	Partial
+	// Hash replaces the value with a short hash, so that equal values can be correlated
+	Hash
+)
+
+// Mask that replaces values in full
+const Mask = "[REDACTED]"
+
+// Struct tag values that control redaction, as in `log:"redact"`
+const (
+	tagName   = "log"
+	tagRedact = "redact"
+	tagOmit   = "-"
+)
+
+// maximum depth of nested values
+const maxDepth = 8
+
+// type of values that can hold anything
+var anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// Patterns for common secrets
+var (
+	JWT          = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	CreditCard   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	AWSAccessKey = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+)
+
+// DefaultKeys are names and patterns of keys with sensitive values
+var DefaultKeys = []string{
+	"authorization",
+	"cookie",
+	"set-cookie",
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*api_key*",
+	"*apikey*",
+}
+
+// DefaultPatterns are patterns of sensitive values
+var DefaultPatterns = []*regexp.Regexp{
+	JWT,
+	CreditCard,
+	AWSAccessKey,
+}
+
+// Redactor masks sensitive values in messages
+type Redactor struct {
+	// case-insensitive names or glob patterns of keys with sensitive values
+	Keys []string
+	// patterns of sensitive text within values
+	Patterns []*regexp.Regexp
+	// how to mask values
+	Style Style
+}
+
+// New returns a redactor with the default keys and patterns
+func New(style Style) *Redactor {
+	return &Redactor{
+		Keys:     DefaultKeys,
+		Patterns: DefaultPatterns,
+		Style:    style,
+	}
+}
+
+// Hook masks sensitive values in a message; add it to a logger with AddHook.
+// The text of the message is matched against the patterns as well.
+func (r *Redactor) Hook(m *msg.Message) bool {
+	if text, changed := r.text(m.Text); changed {
+		m.Text = text
+	}
+	m.Data, _ = r.fields(m.Data)
+
+	return true
+}
+
+// Value returns a copy of a value with sensitive data masked, or the
+// value itself if there is nothing to mask
+func (r *Redactor) Value(value interface{}) interface{} {
+	redacted, _ := r.value(reflect.ValueOf(value), 0)
+
+	return interfaceOf(redacted)
+}
+
+// fields masks values in a list of fields, which is copied if anything
+// changes since nested groups may be shared between messages
+func (r *Redactor) fields(fields []msg.Field) ([]msg.Field, bool) {
+	copied := false
+	for i, field := range fields {
+		redacted, changed := r.field(field)
+		if !changed {
+			continue
+		}
+		if !copied {
+			fields = append([]msg.Field{}, fields...)
+			copied = true
+		}
+		fields[i] = redacted
+	}
+
+	return fields, copied
+}
+
+// field returns a field with sensitive data masked, and whether it changed
+func (r *Redactor) field(field msg.Field) (msg.Field, bool) {
+	switch field.Kind {
+	case msg.OpenGroupKind:
+		return field, false
+	case msg.GroupKind:
+		if nested, changed := r.fields(field.Group()); changed {
+			return msg.Group(field.Key, nested...), true
+		}
+
+		return field, false
+	}
+
+	if r.isSensitive(field.Key) {
+		return msg.String(field.Key, r.mask(render(field.Value()))), true
+	}
+
+	switch field.Kind {
+	case msg.StringKind:
+		if text, changed := r.text(field.Str); changed {
+			return msg.String(field.Key, text), true
+		}
+	case msg.ErrorKind:
+		if field.Err() != nil {
+			if text, changed := r.text(values.ErrorText(field.Err())); changed {
+				return msg.String(field.Key, text), true
+			}
+		}
+	case msg.AnyKind:
+		if value, changed := r.value(reflect.ValueOf(field.Any), 0); changed {
+			return msg.Any(field.Key, interfaceOf(value)), true
+		}
+	}
+
+	return field, false
+}
+
+// value returns a value with sensitive data masked, and whether it changed.
+// Structs, maps, and collections that change are copied, keeping their types
+// if the masks fit in them. Otherwise, structs are copied into struct types
+// with the same field names and tags, and maps and collections into ones
+// that hold any value.
+func (r *Redactor) value(v reflect.Value, depth int) (reflect.Value, bool) {
+	if !v.IsValid() || depth > maxDepth {
+		return v, false
+	}
+
+	// values with a text representation are matched against the patterns
+	if text, ok := values.Encode(interfaceOf(v)).(string); ok {
+		if masked, changed := r.text(text); changed {
+			return reflect.ValueOf(masked), true
+		}
+
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := r.value(v.Elem(), depth+1)
+		if changed && elem.Type() == v.Type().Elem() {
+			ptr := reflect.New(elem.Type())
+			ptr.Elem().Set(elem)
+
+			return ptr, true
+		}
+
+		return elem, changed
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		if elem, changed := r.value(v.Elem(), depth+1); changed {
+			return elem, true
+		}
+	case reflect.Struct:
+		return r.structure(v, depth)
+	case reflect.Map:
+		return r.mapping(v, depth)
+	case reflect.Slice, reflect.Array:
+		return r.collection(v, depth)
+	}
+
+	return v, false
+}
+
+// structure masks the fields of a struct that are sensitive or tagged with `log:"redact"`,
+// and omits the ones tagged with `log:"-"`
+func (r *Redactor) structure(v reflect.Value, depth int) (reflect.Value, bool) {
+	masked := make([]reflect.Value, v.NumField())
+	changed, fits := false, true
+	for i := range masked {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get(tagName)
+		switch {
+		case field.PkgPath != "":
+			// unexported fields cannot be masked, so they are left out if they need to be
+			if tag == tagRedact || tag == tagOmit || r.isSensitive(field.Name) || r.hasSecret(v.Field(i)) {
+				changed, fits = true, false
+			}
+
+			continue
+		case tag == tagOmit:
+			changed, fits = true, false
+
+			continue
+		case tag == tagRedact || r.isSensitive(field.Name):
+			masked[i] = reflect.ValueOf(r.mask(render(interfaceOf(v.Field(i)))))
+			changed = true
+		default:
+			var c bool
+			masked[i], c = r.value(v.Field(i), depth+1)
+			changed = changed || c
+		}
+		var ok bool
+		masked[i], ok = fit(masked[i], field.Type)
+		fits = fits && ok
+	}
+	if !changed {
+		return v, false
+	}
+	if !fits {
+		return rebuild(v.Type(), masked), true
+	}
+
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	for i, value := range masked {
+		if value.IsValid() {
+			copied.Field(i).Set(value)
+		}
+	}
+
+	return copied, true
+}
+
+// mapping masks the entries of a map with sensitive keys or values
+func (r *Redactor) mapping(v reflect.Value, depth int) (reflect.Value, bool) {
+	keys := v.MapKeys()
+	masked := make([]reflect.Value, len(keys))
+	changed, fits := false, true
+	for i, key := range keys {
+		if r.isSensitive(fmt.Sprint(interfaceOf(key))) {
+			masked[i] = reflect.ValueOf(r.mask(render(interfaceOf(v.MapIndex(key)))))
+			changed = true
+		} else {
+			var c bool
+			masked[i], c = r.value(v.MapIndex(key), depth+1)
+			changed = changed || c
+		}
+		var ok bool
+		masked[i], ok = fit(masked[i], v.Type().Elem())
+		fits = fits && ok
+	}
+	if !changed {
+		return v, false
+	}
+
+	mapType := v.Type()
+	if !fits {
+		mapType = reflect.MapOf(mapType.Key(), anyType)
+	}
+	copied := reflect.MakeMapWithSize(mapType, len(keys))
+	for i, key := range keys {
+		copied.SetMapIndex(key, masked[i])
+	}
+
+	return copied, true
+}
+
+// collection masks the sensitive elements of a slice or array
+func (r *Redactor) collection(v reflect.Value, depth int) (reflect.Value, bool) {
+	masked := make([]reflect.Value, v.Len())
+	changed, fits := false, true
+	for i := range masked {
+		var c, ok bool
+		masked[i], c = r.value(v.Index(i), depth+1)
+		masked[i], ok = fit(masked[i], v.Type().Elem())
+		changed = changed || c
+		fits = fits && ok
+	}
+	if !changed {
+		return v, false
+	}
+
+	var copied reflect.Value
+	switch {
+	case !fits:
+		copied = reflect.MakeSlice(reflect.SliceOf(anyType), len(masked), len(masked))
+	case v.Kind() == reflect.Array:
+		copied = reflect.New(v.Type()).Elem()
+	default:
+		copied = reflect.MakeSlice(v.Type(), len(masked), len(masked))
+	}
+	for i, value := range masked {
+		copied.Index(i).Set(value)
+	}
+
+	return copied, true
+}
+
+// hasSecret returns true if a value that cannot be masked is text that matches the patterns
+func (r *Redactor) hasSecret(v reflect.Value) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	_, changed := r.text(v.String())
+
+	return changed
+}
+
+// isSensitive returns true if a key matches any of the sensitive names or patterns
+func (r *Redactor) isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// text masks all matches of the sensitive patterns in text
+func (r *Redactor) text(text string) (string, bool) {
+	changed := false
+	for _, pattern := range r.Patterns {
+		if pattern.MatchString(text) {
+			text = pattern.ReplaceAllStringFunc(text, r.mask)
+			changed = true
+		}
+	}
+
+	return text, changed
+}
+
+// mask returns a masked version of a sensitive value
+func (r *Redactor) mask(value string) string {
+	switch r.Style {
+	case Partial:
+		n := utf8.RuneCountInString(value)
+		if n <= 8 {
+			return strings.Repeat("*", n)
+		}
+		runes := []rune(value)
+
+		return strings.Repeat("*", n-4) + string(runes[n-4:])
+	case Hash:
+		sum := sha256.Sum256([]byte(value))
+
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	default:
+		return Mask
+	}
+}
+
+// rebuild copies the fields of a struct into a struct type of its own, with
+// the same names and tags and the types of the masked values, leaving out
+// the omitted and unexported fields
+func rebuild(t reflect.Type, masked []reflect.Value) reflect.Value {
+	fields := make([]reflect.StructField, 0, len(masked))
+	kept := make([]reflect.Value, 0, len(masked))
+	for i, value := range masked {
+		if !value.IsValid() {
+			continue
+		}
+		field := t.Field(i)
+		fields = append(fields, reflect.StructField{
+			Name:      field.Name,
+			Type:      value.Type(),
+			Tag:       field.Tag,
+			Anonymous: field.Anonymous && value.Type() == field.Type && embeddable(field.Type),
+		})
+		kept = append(kept, value)
+	}
+
+	copied := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range kept {
+		copied.Field(i).Set(value)
+	}
+
+	return copied
+}
+
+// embeddable returns true if a type can be embedded in a struct type created at run time
+func embeddable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumMethod() == 0 && reflect.PtrTo(t).NumMethod() == 0
+}
+
+// fit returns a masked value converted to a type, and whether it fits in the type
+func fit(value reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	switch {
+	case !value.IsValid():
+		return value, false
+	case value.Type().AssignableTo(t):
+		return value, true
+	case value.Kind() == reflect.String && t.Kind() == reflect.String:
+		return value.Convert(t), true
+	default:
+		return value, false
+	}
+}
+
+// render returns the text of a value that is masked
+func render(value interface{}) string {
+	switch v := values.Encode(value).(type) {
+	case string:
+		return v
+	case values.Raw:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// interfaceOf returns the value held by a reflected value, if it is accessible
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+
+	return v.Interface()
+}
@@ -0,0 +1,150 @@
+package captainslog
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/vmodule"
+)
+
+// registry of hierarchical loggers by name
+var registry = struct {
+	sync.Mutex
+	loggers map[string]*Logger
+}{
+	loggers: map[string]*Logger{},
+}
+
+// Get returns the logger with a dotted name such as "app.db.pool", creating it
+// and its ancestors if needed. Unless they are set, it inherits the level, streams,
+// format, time format, and clock of its closest ancestor that sets them. Loggers
+// without a parent inherit from the default logger.
+func Get(name string) *Logger {
+	if len(name) == 0 {
+		return Default()
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	return get(name)
+}
+
+// get returns the logger with a name, creating it if needed; the registry must be locked
+func get(name string) *Logger {
+	if log, ok := registry.loggers[name]; ok {
+		return log
+	}
+
+	log := &Logger{
+		Name:       name,
+		Level:      levels.NewVar(levels.Inherit),
+		NameCutoff: 15,
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		log.parent = get(name[:i])
+	}
+	registry.loggers[name] = log
+
+	return log
+}
+
+// Names returns the names of all the hierarchical loggers, sorted
+func Names() []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	names := make([]string, 0, len(registry.loggers))
+	for name := range registry.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Parent returns the logger that this one inherits from, if any
+func (log *Logger) Parent() *Logger {
+	if log.parent != nil {
+		return log.parent
+	}
+	// the default logger is the root of the hierarchy, unless it belongs to it
+	root := Default()
+	if root == log || root.parent != nil {
+		return nil
+	}
+
+	return root
+}
+
+// EffectiveLevel returns the minimum level of messages that are logged,
+// taking inherited levels into account
+func (log *Logger) EffectiveLevel() int {
+	for l := log; l != nil; l = l.Parent() {
+		if level := l.GetLevel(); level != levels.Inherit {
+			return level
+		}
+	}
+
+	return levels.Debug
+}
+
+// vmodule returns the spec that overrides the level by call site, if any
+func (log *Logger) vmodule() *vmodule.Spec {
+	for l := log; l != nil; l = l.Parent() {
+		if l.VModule != nil {
+			return l.VModule
+		}
+	}
+
+	return nil
+}
+
+// threshold returns the minimum level of messages logged from a call site
+func (log *Logger) threshold(spec *vmodule.Spec, pc uintptr) int {
+	if spec != nil {
+		if level, ok := spec.Level(pc); ok {
+			return level
+		}
+	}
+
+	return log.EffectiveLevel()
+}
+
+// streams returns the logger whose streams and colors are used
+func (log *Logger) streams() *Logger {
+	for l := log; l != nil; l = l.Parent() {
+		if l.Stdout != nil || l.Stderr != nil {
+			return l
+		}
+	}
+
+	return &Logger{HasColor: true, Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+// format returns the format used by the logger
+func (log *Logger) format() msg.Format {
+	for l := log; l != nil; l = l.Parent() {
+		if l.Format != nil {
+			return l.Format
+		}
+	}
+
+	return format.Flat
+}
+
+// timeFormat returns the time format used by the logger
+func (log *Logger) timeFormat() string {
+	for l := log; l != nil; l = l.Parent() {
+		if len(l.TimeFormat) > 0 {
+			return l.TimeFormat
+		}
+	}
+
+	return ISO8601
+}
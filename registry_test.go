@@ -0,0 +1,119 @@
+package captainslog_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/format"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/preflight"
+	"vincent.click/pkg/captainslog/v2/vmodule"
+)
+
+func ExampleGet() {
+	captainslog.Get("app").SetLevel(levels.Info)
+	captainslog.Get("app.db").SetLevel(levels.Trace)
+
+	// inherits the level of app.db
+	log := captainslog.Get("app.db.pool")
+	log.Trace("connection opened")
+}
+
+func TestGet(test *testing.T) {
+	t := preflight.Unit(test)
+
+	pool := captainslog.Get("enterprise.engineering.warp")
+
+	// should return the same logger every time
+	t.Expect(captainslog.Get("enterprise.engineering.warp")).Equals(pool)
+	t.Expect(captainslog.Get("")).Equals(captainslog.Default())
+
+	// should create the ancestors
+	t.Expect(pool.Name).Equals("enterprise.engineering.warp")
+	t.Expect(pool.GetLevel()).Equals(levels.Inherit)
+	t.Expect(pool.Parent()).Equals(captainslog.Get("enterprise.engineering"))
+	t.Expect(pool.Parent().Parent()).Equals(captainslog.Get("enterprise"))
+	t.Expect(captainslog.Get("enterprise").Parent()).Equals(captainslog.Default())
+	t.Expect(captainslog.Default().Parent()).Is().Nil()
+	t.Expect(captainslog.NewLogger().Parent()).Equals(captainslog.Default())
+
+	names := []string{}
+	for _, name := range captainslog.Names() {
+		if strings.HasPrefix(name, "enterprise") {
+			names = append(names, name)
+		}
+	}
+	t.Expect(names).Equals([]string{"enterprise", "enterprise.engineering", "enterprise.engineering.warp"})
+}
+
+func TestInheritance(test *testing.T) {
+	t := preflight.Unit(test)
+
+	stdout, stderr := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		ship := captainslog.Get("voyager")
+		ship.SetLevel(levels.Warn)
+		ship.Stdout = stdout
+		ship.Stderr = stderr
+		ship.HasColor = false
+		ship.TimeFormat = "2006"
+		ship.Clock = getLogger().Clock
+		ship.Format = format.Flat
+
+		bridge := captainslog.Get("voyager.bridge")
+		sickbay := captainslog.Get("voyager.sickbay")
+		sickbay.SetLevel(levels.Trace)
+
+		bridge.Info("hidden")
+		bridge.Warn("red alert")
+		sickbay.Debug("please state the nature of the medical emergency")
+
+		// changes should apply at runtime
+		ship.SetLevel(levels.Info)
+		bridge.Info("course laid in")
+		sickbay.SetLevel(levels.Inherit)
+		sickbay.Debug("hidden")
+	})
+
+	t.Expect(stdout).HasLength(2)
+	t.Expect(stderr).HasLength(1)
+
+	stderr[0].Message.Equals("red alert")
+	stderr[0].Name.Equals("voyager.bridge")
+	stderr[0].Time.Equals("2364")
+	stdout[0].Message.Equals("please state the nature of the medical emergency")
+	stdout[1].Message.Equals("course laid in")
+
+	t.Expect(captainslog.Get("voyager.bridge").EffectiveLevel()).Equals(levels.Info)
+	t.Expect(captainslog.Get("voyager.bridge").Enabled(levels.Debug)).Equals(false)
+}
+
+func TestVModule(test *testing.T) {
+	t := preflight.Unit(test)
+
+	spec, err := vmodule.Parse("registry_test=warn")
+	t.Expect(err).Is().Nil()
+
+	stdout, stderr := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log := getLogger()
+		log.Stdout = stdout
+		log.Stderr = stderr
+		log.VModule = spec
+
+		log.Info("hidden")
+		log.Error("hull breach")
+
+		t.Expect(log.Enabled(levels.Info)).Equals(false)
+		t.Expect(log.Enabled(levels.Warn)).Equals(true)
+
+		// messages from other files use the level of the logger
+		t.Expect(spec.Set("log_test=warn")).Is().Nil()
+		log.Info("impulse power")
+	})
+
+	t.Expect(stdout).HasLength(1)
+	t.Expect(stderr).HasLength(1)
+	stderr[0].Message.Equals("hull breach")
+	stdout[0].Message.Equals("impulse power")
+}
@@ -0,0 +1,268 @@
+package sample
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// Policy keeps the first messages with the same level and template
+// in each interval, and then every nth message after that
+type Policy struct {
+	// number of messages to keep in each interval
+	First uint64
+	// keep every nth message after the first ones; use 0 to drop them all
+	Thereafter uint64
+	// length of each interval; use 0 to keep every message
+	Interval time.Duration
+}
+
+// key identifies messages that are sampled together
+type key struct {
+	level    int
+	template string
+}
+
+// counter counts the messages in the current interval
+type counter struct {
+	start time.Time
+	count uint64
+}
+
+// Sampler is a filter that limits the number of messages with the same
+// level and template. It is safe to share between loggers.
+type Sampler struct {
+	// interval between summaries of dropped messages; use 0 to disable them
+	Summary time.Duration
+	// clock that stamps the summaries printed by Flush; set it to the
+	// clock of the logger so that they are in order with its messages
+	Clock clock.Clock
+
+	mutex    sync.Mutex
+	fallback Policy
+	policies map[int]Policy
+	counters map[key]*counter
+	dropped  map[int]uint64
+	// messages dropped since the last summary
+	pending     map[int]uint64
+	lastSummary time.Time
+	// time when counters of past intervals were last removed
+	lastSweep time.Time
+	// properties used to print the summary, from the last dropped message
+	template msg.Message
+	// prints the summary if no message arrives after it is due
+	timer *time.Timer
+}
+
+// New returns a sampler that applies a policy to every level
+func New(policy Policy) *Sampler {
+	return &Sampler{
+		Summary:  time.Minute,
+		Clock:    clock.System,
+		fallback: policy,
+		policies: map[int]Policy{},
+		counters: map[key]*counter{},
+		dropped:  map[int]uint64{},
+		pending:  map[int]uint64{},
+	}
+}
+
+// SetPolicy sets the policy for a single level
+func (s *Sampler) SetPolicy(level int, policy Policy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.policies[level] = policy
+}
+
+// Policy returns the policy for a level
+func (s *Sampler) Policy(level int) Policy {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.policy(level)
+}
+
+// Dropped returns the number of messages that were dropped at each level
+func (s *Sampler) Dropped() map[int]uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dropped := make(map[int]uint64, len(s.dropped))
+	for level, n := range s.dropped {
+		dropped[level] = n
+	}
+
+	return dropped
+}
+
+// Tracked returns the number of level and template pairs being counted
+func (s *Sampler) Tracked() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.counters)
+}
+
+// Allow returns true if a message should be printed. When a summary is due,
+// it is printed before the message using the format and streams of the last
+// dropped message. If no message arrives, it is printed by Flush, which is
+// called once the summary interval has passed.
+func (s *Sampler) Allow(message *msg.Message) bool {
+	s.mutex.Lock()
+	allow := s.sample(message)
+	if s.lastSummary.IsZero() {
+		s.lastSummary = message.Time
+	}
+	var summary *msg.Message
+	if message.Time.Sub(s.lastSummary) >= s.Summary {
+		summary = s.summarize(message.Time)
+	}
+	s.mutex.Unlock()
+
+	printSummary(summary)
+
+	return allow
+}
+
+// Flush prints the summary of the messages dropped since the last one, if any,
+// stamped with the time of the clock
+func (s *Sampler) Flush() {
+	now := clock.System.Now()
+	if s.Clock != nil {
+		now = s.Clock.Now()
+	}
+
+	s.mutex.Lock()
+	summary := s.summarize(now)
+	s.mutex.Unlock()
+
+	printSummary(summary)
+}
+
+// policy returns the policy for a level
+func (s *Sampler) policy(level int) Policy {
+	if policy, ok := s.policies[level]; ok {
+		return policy
+	}
+
+	return s.fallback
+}
+
+// sample counts a message and returns true if it should be kept
+func (s *Sampler) sample(message *msg.Message) bool {
+	policy := s.policy(message.Level)
+	if policy.Interval <= 0 {
+		return true
+	}
+
+	s.sweep(message.Time, policy.Interval)
+	k := key{message.Level, message.Template}
+	c, ok := s.counters[k]
+	if !ok {
+		c = &counter{start: message.Time}
+		s.counters[k] = c
+	}
+	if message.Time.Sub(c.start) >= policy.Interval {
+		c.start = message.Time
+		c.count = 0
+	}
+	c.count++
+
+	if c.count <= policy.First {
+		return true
+	}
+	if policy.Thereafter > 0 && (c.count-policy.First)%policy.Thereafter == 0 {
+		return true
+	}
+
+	s.drop(message)
+
+	return false
+}
+
+// sweep removes the counters whose interval has passed, at most once per interval
+func (s *Sampler) sweep(now time.Time, interval time.Duration) {
+	if now.Sub(s.lastSweep) < interval {
+		return
+	}
+	for k, c := range s.counters {
+		if now.Sub(c.start) >= s.policy(k.level).Interval {
+			delete(s.counters, k)
+		}
+	}
+	s.lastSweep = now
+}
+
+// drop counts a dropped message and schedules a summary of it
+func (s *Sampler) drop(message *msg.Message) {
+	s.dropped[message.Level]++
+	s.pending[message.Level]++
+	s.template = msg.Message{
+		TimeFormat: message.TimeFormat,
+		Name:       message.Name,
+		Threshold:  message.Threshold,
+		HasColor:   message.HasColor,
+		ForceColor: message.ForceColor,
+		Theme:      message.Theme,
+		Labels:     message.Labels,
+		Stdout:     message.Stdout,
+		Stderr:     message.Stderr,
+		Print:      message.Print,
+	}
+	if s.timer == nil && s.Summary > 0 {
+		s.timer = time.AfterFunc(s.Summary, s.Flush)
+	}
+}
+
+// summarize returns a message reporting the messages dropped since the last
+// summary, or nil if there are none or the summary is below the threshold
+func (s *Sampler) summarize(now time.Time) *msg.Message {
+	if s.Summary <= 0 || len(s.pending) == 0 {
+		return nil
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	var total uint64
+	lvls := make([]int, 0, len(s.pending))
+	for level, n := range s.pending {
+		lvls = append(lvls, level)
+		total += n
+	}
+	sort.Ints(lvls)
+
+	data := make([]msg.Field, 0, len(lvls))
+	for _, level := range lvls {
+		data = append(data, msg.Int64(levels.Name(level), int64(s.pending[level])))
+	}
+
+	s.pending = map[int]uint64{}
+	s.lastSummary = now
+
+	summary := s.template
+	summary.Time = now
+	summary.Text = fmt.Sprintf("sampled out %d messages", total)
+	summary.Template = "sampled out %d messages"
+	summary.Level = levels.Warn
+	summary.Data = data
+	if summary.Level < summary.Threshold {
+		return nil
+	}
+
+	return &summary
+}
+
+// printSummary prints a summary, if there is one
+func printSummary(summary *msg.Message) {
+	if summary != nil {
+		summary.Print(summary)
+	}
+}
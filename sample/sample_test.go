@@ -0,0 +1,181 @@
+package sample_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/clock"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/sample"
+	"vincent.click/pkg/preflight"
+)
+
+var stardate = time.Date(2364, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestSampler(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{
+		First:      2,
+		Thereafter: 3,
+		Interval:   time.Second,
+	})
+
+	kept := 0
+	for i := 0; i < 10; i++ {
+		if sampler.Allow(createMessage(levels.Debug, "scan", stardate)) {
+			kept++
+		}
+	}
+
+	// should keep the first 2 and then every 3rd message
+	t.Expect(kept).Equals(4)
+	t.Expect(sampler.Dropped()).Equals(map[int]uint64{levels.Debug: 6})
+
+	// messages with other templates and levels are counted separately
+	t.Expect(sampler.Allow(createMessage(levels.Debug, "status", stardate))).Equals(true)
+	t.Expect(sampler.Allow(createMessage(levels.Info, "scan", stardate))).Equals(true)
+
+	// counts reset after the interval
+	t.Expect(sampler.Allow(createMessage(levels.Debug, "scan", stardate.Add(time.Second)))).Equals(true)
+}
+
+func TestPolicy(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{
+		First:    1,
+		Interval: time.Second,
+	})
+	sampler.SetPolicy(levels.Error, sample.Policy{})
+
+	t.Expect(sampler.Policy(levels.Error)).Equals(sample.Policy{})
+	t.Expect(sampler.Policy(levels.Info).First).Equals(uint64(1))
+
+	for i := 0; i < 3; i++ {
+		t.Expect(sampler.Allow(createMessage(levels.Error, "breach", stardate))).Equals(true)
+	}
+	t.Expect(sampler.Allow(createMessage(levels.Info, "breach", stardate))).Equals(true)
+	t.Expect(sampler.Allow(createMessage(levels.Info, "breach", stardate))).Equals(false)
+}
+
+func TestSummary(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{
+		First:    1,
+		Interval: time.Hour,
+	})
+	sampler.Summary = time.Minute
+
+	var summaries []*msg.Message
+	send := func(level int, offset time.Duration) {
+		message := createMessage(level, "scan", stardate.Add(offset))
+		message.Print = func(summary *msg.Message) {
+			summaries = append(summaries, summary)
+		}
+		sampler.Allow(message)
+	}
+
+	send(levels.Debug, 0)
+	send(levels.Debug, time.Second)
+	send(levels.Debug, 2*time.Second)
+	send(levels.Info, 3*time.Second)
+	send(levels.Info, 4*time.Second)
+
+	// no summary until the interval has passed
+	t.Expect(summaries).HasLength(0)
+
+	send(levels.Info, time.Minute)
+
+	t.Expect(summaries).HasLength(1)
+	t.Expect(summaries[0].Text).Equals("sampled out 4 messages")
+	t.Expect(summaries[0].Level).Equals(levels.Warn)
+	t.Expect(summaries[0].Data).Equals([]msg.Field{
+		msg.Int64("debug", 2),
+		msg.Int64("info", 2),
+	})
+}
+
+func TestSummaryThreshold(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{Interval: time.Hour})
+	sampler.Summary = time.Minute
+
+	printed := 0
+	for i := 0; i < 2; i++ {
+		message := createMessage(levels.Error, "breach", stardate.Add(time.Duration(i)*time.Minute))
+		message.Threshold = levels.Error
+		message.Print = func(*msg.Message) {
+			printed++
+		}
+		sampler.Allow(message)
+	}
+
+	// a warning should not be printed by a logger that only prints errors
+	t.Expect(printed).Equals(0)
+}
+
+func TestFlush(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{Interval: time.Hour})
+	sampler.Summary = 20 * time.Millisecond
+	sampler.Clock = clock.Fixed(stardate.Add(time.Hour))
+
+	summaries := make(chan *msg.Message, 1)
+	message := createMessage(levels.Debug, "scan", stardate)
+	message.Print = func(summary *msg.Message) {
+		summaries <- summary
+	}
+	t.Expect(sampler.Allow(message)).Equals(false)
+
+	// drops before a quiet period should still be reported
+	select {
+	case summary := <-summaries:
+		t.Expect(summary.Text).Equals("sampled out 1 messages")
+		t.Expect(summary.Data).Equals([]msg.Field{msg.Int64("debug", 1)})
+		// the summary should be stamped with the time it is printed
+		t.Expect(summary.Time).Equals(stardate.Add(time.Hour))
+	case <-time.After(5 * time.Second):
+		t.T.Fatal("summary was not printed")
+	}
+
+	sampler.Flush()
+	t.Expect(summaries).HasLength(0)
+}
+
+func TestSweep(test *testing.T) {
+	t := preflight.Unit(test)
+
+	sampler := sample.New(sample.Policy{First: 1, Interval: time.Second})
+	sampler.Summary = 0
+	for i := 0; i < 100; i++ {
+		sampler.Allow(createMessage(levels.Info, fmt.Sprintf("scan %d", i), stardate))
+	}
+	t.Expect(sampler.Tracked()).Equals(100)
+
+	// counters of past intervals should be removed
+	sampler.Allow(createMessage(levels.Info, "scan", stardate.Add(time.Second)))
+	t.Expect(sampler.Tracked()).Equals(1)
+}
+
+/**
+ * Test Helpers
+ */
+func createMessage(level int, template string, now time.Time) *msg.Message {
+	return &msg.Message{
+		Time:      now,
+		Name:      "captainslog",
+		Text:      template,
+		Template:  template,
+		Level:     level,
+		Threshold: levels.Trace,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}
+}
@@ -0,0 +1,88 @@
+package signals
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/levels"
+)
+
+// ErrUnsupported is returned on platforms without the signals
+var ErrUnsupported = errors.New("signals are not supported on this platform")
+
+// Toggle changes the level of a logger when the process receives signals:
+// SIGUSR1 raises the verbosity one step, and SIGUSR2 resets it to the level
+// the logger had when the toggle was installed. Each change is logged.
+type Toggle struct {
+	log *captainslog.Logger
+	// level that the logger is reset to
+	level   int
+	signals chan os.Signal
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Install starts changing the level of a logger on SIGUSR1 and SIGUSR2.
+// The logger must have a Level, as all loggers returned by captainslog.NewLogger
+// and captainslog.Get do.
+func Install(log *captainslog.Logger) (*Toggle, error) {
+	if !supported {
+		return nil, ErrUnsupported
+	}
+	if log.Level == nil {
+		return nil, captainslog.ErrNoLevel
+	}
+
+	t := &Toggle{
+		log:     log,
+		level:   log.GetLevel(),
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(t.signals, raise, reset)
+	go t.listen()
+
+	return t, nil
+}
+
+// Remove stops handling the signals; the logger keeps its current level
+func (t *Toggle) Remove() {
+	t.once.Do(func() {
+		signal.Stop(t.signals)
+		close(t.done)
+	})
+}
+
+// listen changes the level for each signal until the toggle is removed
+func (t *Toggle) listen() {
+	for {
+		select {
+		case sig := <-t.signals:
+			t.handle(sig)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// handle changes the level of the logger for a signal and logs the change
+func (t *Toggle) handle(sig os.Signal) {
+	level := t.level
+	text := "log level reset to %s"
+	if sig == raise {
+		level = t.log.EffectiveLevel() - 1
+		if level < levels.Trace {
+			level = levels.Trace
+		}
+		text = "log level raised to %s"
+	}
+	t.log.SetLevel(level)
+
+	// the change is logged even if it is below the new level
+	m := t.log.Field("signal", sig.String())
+	m.Threshold = levels.Trace
+	m.Info(text, levels.Name(t.log.EffectiveLevel()))
+}
@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package signals
+
+import (
+	"os"
+)
+
+// there are no signals to raise the verbosity and reset it
+var (
+	raise os.Signal
+	reset os.Signal
+)
+
+const supported = false
@@ -0,0 +1,16 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package signals
+
+import (
+	"syscall"
+)
+
+// signals that raise the verbosity and reset it
+var (
+	raise = syscall.SIGUSR1
+	reset = syscall.SIGUSR2
+)
+
+const supported = true
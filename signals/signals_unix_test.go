@@ -0,0 +1,140 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package signals_test
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/preflight"
+	"vincent.click/pkg/captainslog/v2/signals"
+)
+
+func TestToggle(test *testing.T) {
+	t := preflight.Unit(test)
+
+	var log *captainslog.Logger
+	stdout, _ := t.ExpectLogged(func(stdout *os.File, stderr *os.File) {
+		log = captainslog.NewLogger()
+		log.Name = "signals"
+		log.HasColor = false
+		log.Stdout = stdout
+		log.Stderr = stderr
+		log.SetLevel(levels.Info)
+
+		logged := make(chan string, 1)
+		log.AddHook(func(m *msg.Message) bool {
+			logged <- m.Text
+
+			return true
+		})
+
+		toggle, err := signals.Install(log)
+		t.Expect(err).Is().Nil()
+
+		for _, sig := range []syscall.Signal{syscall.SIGUSR1, syscall.SIGUSR1, syscall.SIGUSR1, syscall.SIGUSR2} {
+			t.Expect(syscall.Kill(os.Getpid(), sig)).Is().Nil()
+			wait(t, logged)
+		}
+
+		// should stop changing the level once removed
+		toggle.Remove()
+		toggle.Remove()
+		log.SetLevel(levels.Warn)
+
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, syscall.SIGUSR1)
+		defer signal.Stop(received)
+		t.Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).Is().Nil()
+		<-received
+	})
+
+	t.Expect(stdout).HasLength(4)
+	stdout[0].Message.Equals("log level raised to debug")
+	stdout[0].Fields.Equals(`signal="user defined signal 1"`)
+	stdout[1].Message.Equals("log level raised to trace")
+	stdout[2].Message.Equals("log level raised to trace")
+	stdout[3].Message.Equals("log level reset to info")
+	stdout[3].Fields.Equals(`signal="user defined signal 2"`)
+
+	t.Expect(log.GetLevel()).Equals(levels.Warn)
+}
+
+func TestToggleWhileLogging(test *testing.T) {
+	t := preflight.Unit(test)
+
+	log := captainslog.NewLogger()
+	log.Stdout, log.Stderr = discard(t), discard(t)
+	logged := make(chan string, 1)
+	log.AddHook(func(m *msg.Message) bool {
+		if m.Template != "scan %d" {
+			logged <- m.Text
+		}
+
+		return true
+	})
+
+	toggle, err := signals.Install(log)
+	t.Expect(err).Is().Nil()
+	defer toggle.Remove()
+
+	// changes should be safe while logging, which the race detector checks
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				log.Trace("scan %d", i)
+			}
+		}
+	}()
+	for _, sig := range []syscall.Signal{syscall.SIGUSR1, syscall.SIGUSR1, syscall.SIGUSR2} {
+		t.Expect(syscall.Kill(os.Getpid(), sig)).Is().Nil()
+		wait(t, logged)
+	}
+	close(done)
+	<-stopped
+
+	t.Expect(log.GetLevel()).Equals(levels.Debug)
+}
+
+func TestNoLevel(test *testing.T) {
+	t := preflight.Unit(test)
+
+	toggle, err := signals.Install(&captainslog.Logger{})
+	t.Expect(toggle).Is().Nil()
+	t.Expect(err).Equals(captainslog.ErrNoLevel)
+}
+
+/**
+ * Test Helpers
+ */
+
+func discard(t *preflight.Test) *os.File {
+	file, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	t.Expect(err).Is().Nil()
+	t.T.Cleanup(func() {
+		file.Close()
+	})
+
+	return file
+}
+
+func wait(t *preflight.Test, logged chan string) {
+	select {
+	case <-logged:
+	case <-time.After(5 * time.Second):
+		t.T.Fatal("signal was not handled")
+	}
+}
@@ -0,0 +1,87 @@
+package tty
+
+import (
+	"os"
+	"sync/atomic"
+
+	"github.com/mattn/go-isatty"
+)
+
+// continuous integration services that display colors in their logs
+var colorCI = []string{
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"TRAVIS",
+	"DRONE",
+}
+
+// a stream and whether it is a terminal
+type result struct {
+	stream   *os.File
+	terminal bool
+}
+
+// results for the standard streams, which are checked once since they are
+// used for the lifetime of the process
+var stdout, stderr atomic.Value
+
+// IsTerminal returns true if a stream is a terminal. The result
+// is cached for the standard streams.
+func IsTerminal(stream *os.File) bool {
+	var cached *atomic.Value
+	switch {
+	case stream == nil:
+		return false
+	case stream == os.Stdout:
+		cached = &stdout
+	case stream == os.Stderr:
+		cached = &stderr
+	default:
+		return isTerminal(stream)
+	}
+
+	if r, ok := cached.Load().(result); ok && r.stream == stream {
+		return r.terminal
+	}
+	terminal := isTerminal(stream)
+	cached.Store(result{stream, terminal})
+
+	return terminal
+}
+
+// isTerminal checks whether a stream is a terminal
+func isTerminal(stream *os.File) bool {
+	fd := stream.Fd()
+
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// Color returns true if colors should be used on a stream. Colors are
+// disabled by NO_COLOR and enabled by FORCE_COLOR unless it is "0" or
+// "false"; otherwise they are used on terminals unless TERM is "dumb",
+// and in continuous integration services that display them.
+func Color(stream *os.File) bool {
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); len(force) > 0 {
+		return force != "0" && force != "false"
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if IsTerminal(stream) {
+		return true
+	}
+	if len(os.Getenv("CI")) > 0 {
+		for _, name := range colorCI {
+			if len(os.Getenv(name)) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+package tty_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/tty"
+	"vincent.click/pkg/preflight"
+)
+
+func TestIsTerminal(test *testing.T) {
+	t := preflight.Unit(test)
+
+	r, w, err := os.Pipe()
+	t.Expect(err).Is().Nil()
+	defer r.Close()
+	defer w.Close()
+
+	t.Expect(tty.IsTerminal(w)).Equals(false)
+	t.Expect(tty.IsTerminal(w)).Equals(false)
+	t.Expect(tty.IsTerminal(nil)).Equals(false)
+	t.Expect(tty.IsTerminal(os.Stderr)).Equals(tty.IsTerminal(os.Stderr))
+}
+
+func TestIsTerminalStreams(test *testing.T) {
+	t := preflight.Unit(test)
+
+	released := make(chan struct{})
+	func() {
+		r, w, err := os.Pipe()
+		t.Expect(err).Is().Nil()
+		defer r.Close()
+		defer w.Close()
+
+		t.Expect(tty.IsTerminal(w)).Equals(false)
+		runtime.SetFinalizer(w, func(*os.File) {
+			close(released)
+		})
+	}()
+
+	// streams should not be kept alive after they are checked
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		select {
+		case <-released:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.T.Fatal("stream was not released")
+}
+
+func TestColor(test *testing.T) {
+	t := preflight.Unit(test)
+
+	r, w, err := os.Pipe()
+	t.Expect(err).Is().Nil()
+	defer r.Close()
+	defer w.Close()
+
+	for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "TERM", "CI", "GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "CIRCLECI", "TRAVIS", "DRONE"} {
+		t.T.Setenv(name, "")
+	}
+
+	// pipes are not terminals
+	t.Expect(tty.Color(w)).Equals(false)
+
+	// continuous integration services that display colors
+	t.T.Setenv("CI", "true")
+	t.Expect(tty.Color(w)).Equals(false)
+	t.T.Setenv("GITHUB_ACTIONS", "true")
+	t.Expect(tty.Color(w)).Equals(true)
+
+	// TERM=dumb disables colors
+	t.T.Setenv("TERM", "dumb")
+	t.Expect(tty.Color(w)).Equals(false)
+
+	// FORCE_COLOR enables colors unless it is 0 or false
+	t.T.Setenv("FORCE_COLOR", "1")
+	t.Expect(tty.Color(w)).Equals(true)
+	t.T.Setenv("FORCE_COLOR", "0")
+	t.Expect(tty.Color(w)).Equals(false)
+	t.T.Setenv("FORCE_COLOR", "false")
+	t.Expect(tty.Color(w)).Equals(false)
+
+	// NO_COLOR takes precedence
+	t.T.Setenv("FORCE_COLOR", "1")
+	t.T.Setenv("NO_COLOR", "1")
+	t.Expect(tty.Color(w)).Equals(false)
+}
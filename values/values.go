@@ -0,0 +1,135 @@
+package values
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Encoder converts a value into a simpler one that formatters know how to print
+type Encoder func(value interface{}) interface{}
+
+// Raw is a value that is already encoded as JSON
+type Raw string
+
+// registry of encoders by type
+var (
+	encoders = map[reflect.Type]Encoder{}
+	mutex    sync.RWMutex
+)
+
+func init() {
+	Register(time.Time{}, func(value interface{}) interface{} {
+		return value.(time.Time).Format(time.RFC3339Nano)
+	})
+	Register(time.Duration(0), func(value interface{}) interface{} {
+		return value.(time.Duration).String()
+	})
+	Register([]byte{}, func(value interface{}) interface{} {
+		b := value.([]byte)
+		if utf8.Valid(b) {
+			return string(b)
+		}
+
+		return base64.StdEncoding.EncodeToString(b)
+	})
+}
+
+// Register sets the encoder used for values with the same type as the example
+func Register(example interface{}, encoder Encoder) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	encoders[reflect.TypeOf(example)] = encoder
+}
+
+// ErrorText returns the message of an error. Errors that are nil pointers are
+// printed in Go syntax, since calling their Error method could panic.
+func ErrorText(err error) (text string) {
+	if err == nil {
+		return "<nil>"
+	}
+	ref := reflect.ValueOf(err)
+	if ref.Kind() == reflect.Ptr && ref.IsNil() {
+		return fmt.Sprintf("%#v", err)
+	}
+
+	// methods of user-defined types might panic
+	defer func() {
+		if r := recover(); r != nil {
+			text = fmt.Sprintf("%%!v(PANIC=%v)", r)
+		}
+	}()
+
+	return err.Error()
+}
+
+// Unregister removes the encoder used for values with the same type as the example
+func Unregister(example interface{}) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	delete(encoders, reflect.TypeOf(example))
+}
+
+// Encode returns a simpler representation of a value, using the encoder
+// registered for its type or the first interface it implements out of
+// error, fmt.Stringer, encoding.TextMarshaler, and json.Marshaler.
+// Pointers are dereferenced and other values are returned unchanged.
+func Encode(value interface{}) (encoded interface{}) {
+	if value == nil {
+		return nil
+	}
+
+	mutex.RLock()
+	encoder, ok := encoders[reflect.TypeOf(value)]
+	mutex.RUnlock()
+
+	ref := reflect.ValueOf(value)
+	if ref.Kind() == reflect.Ptr && ref.IsNil() {
+		return nil
+	}
+
+	// methods of user-defined types might panic
+	defer func() {
+		if r := recover(); r != nil {
+			encoded = fmt.Sprintf("%%!v(PANIC=%v)", r)
+		}
+	}()
+
+	if ok {
+		return encoder(value)
+	}
+
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return err.Error()
+		}
+
+		return string(text)
+	case json.Marshaler:
+		raw, err := v.MarshalJSON()
+		if err != nil {
+			return err.Error()
+		}
+
+		return Raw(raw)
+	}
+
+	if ref.Kind() == reflect.Ptr {
+		return Encode(ref.Elem().Interface())
+	}
+
+	return value
+}
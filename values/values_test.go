@@ -0,0 +1,92 @@
+package values_test
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/values"
+	"vincent.click/pkg/preflight"
+)
+
+type stardate float64
+
+type ship struct {
+	Name string
+}
+
+func (s ship) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.Name + `"`), nil
+}
+
+type rank struct{}
+
+func (*rank) String() string {
+	return "captain"
+}
+
+func TestEncode(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.Expect(values.Encode(nil)).Is().Nil()
+	t.Expect(values.Encode("picard")).Equals("picard")
+	t.Expect(values.Encode(1701)).Equals(1701)
+	t.Expect(values.Encode(2 * time.Second)).Equals("2s")
+	t.Expect(values.Encode(time.Date(2364, 1, 2, 3, 4, 5, 6, time.UTC))).Equals("2364-01-02T03:04:05.000000006Z")
+	t.Expect(values.Encode([]byte("engage"))).Equals("engage")
+	t.Expect(values.Encode([]byte{0xff})).Equals("/w==")
+	t.Expect(values.Encode(errors.New("warp core breach"))).Equals("warp core breach")
+	t.Expect(values.Encode(net.IPv4(10, 0, 0, 1))).Equals("10.0.0.1")
+	t.Expect(values.Encode(big.NewInt(47))).Equals("47")
+	t.Expect(values.Encode(ship{"enterprise"})).Equals(values.Raw(`"enterprise"`))
+	t.Expect(values.Encode(&rank{})).Equals("captain")
+}
+
+func TestEncodePointer(test *testing.T) {
+	t := preflight.Unit(test)
+
+	n := 1701
+	var empty *int
+
+	t.Expect(values.Encode(&n)).Equals(1701)
+	t.Expect(values.Encode(empty)).Is().Nil()
+}
+
+func TestRegister(test *testing.T) {
+	t := preflight.Unit(test)
+
+	values.Register(stardate(0), func(value interface{}) interface{} {
+		return "stardate " + big.NewFloat(float64(value.(stardate))).Text('f', 1)
+	})
+	defer values.Unregister(stardate(0))
+
+	t.Expect(values.Encode(stardate(41153.7))).Equals("stardate 41153.7")
+}
+
+func TestErrorText(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.Expect(values.ErrorText(errors.New("hull breach"))).Equals("hull breach")
+	t.Expect(values.ErrorText(nil)).Equals("<nil>")
+
+	// nil pointers and panics should not crash the caller
+	t.Expect(values.ErrorText((*breach)(nil))).Equals("(*values_test.breach)(nil)")
+	t.Expect(values.ErrorText(&breach{})).Equals("%!v(PANIC=deck unknown)")
+}
+
+/**
+ * Test Helpers
+ */
+type breach struct {
+	deck *int
+}
+
+func (b *breach) Error() string {
+	if b.deck == nil {
+		panic("deck unknown")
+	}
+
+	return "breach"
+}
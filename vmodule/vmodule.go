@@ -0,0 +1,153 @@
+package vmodule
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"vincent.click/pkg/captainslog/v2/levels"
+)
+
+// Rule sets the level of messages logged from matching packages or files
+type Rule struct {
+	// glob pattern of a package path or file path without the .go extension,
+	// which is matched against the same number of trailing path segments
+	Pattern string
+	Level   int
+}
+
+// rules and the levels they resolved to for each call site
+type state struct {
+	rules []Rule
+	// call site -> level, or noMatch
+	cache sync.Map
+}
+
+// cached result of call sites that no rule matches
+const noMatch = levels.Inherit - 1
+
+// Spec is a list of rules like "app/db=trace,app/http/*=warn" that sets the
+// level of messages by the package or file of their call site. It implements
+// flag.Value and is safe to share between loggers.
+type Spec struct {
+	state atomic.Value
+}
+
+// Parse returns a spec of comma-separated pattern=level rules
+func Parse(spec string) (*Spec, error) {
+	s := &Spec{}
+	if err := s.Set(spec); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Set replaces the rules of the spec
+func (s *Spec) Set(spec string) error {
+	rules := []Rule{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf("invalid vmodule rule %q", item)
+		}
+		pattern := strings.TrimSuffix(strings.TrimSpace(parts[0]), ".go")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid vmodule pattern %q: %w", pattern, err)
+		}
+		level, err := levels.Parse(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid vmodule rule %q: %w", item, err)
+		}
+		rules = append(rules, Rule{pattern, level})
+	}
+	s.state.Store(&state{rules: rules})
+
+	return nil
+}
+
+// Rules returns the rules of the spec
+func (s *Spec) Rules() []Rule {
+	st, _ := s.state.Load().(*state)
+	if st == nil {
+		return nil
+	}
+
+	return append([]Rule{}, st.rules...)
+}
+
+// String returns the spec in the form it is parsed from
+func (s *Spec) String() string {
+	if s == nil {
+		return ""
+	}
+	items := []string{}
+	for _, rule := range s.Rules() {
+		items = append(items, rule.Pattern+"="+levels.Name(rule.Level))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// Level returns the level set by the first rule that matches the package
+// or file of a call site, which is cached for the next calls
+func (s *Spec) Level(pc uintptr) (int, bool) {
+	st, _ := s.state.Load().(*state)
+	if st == nil || len(st.rules) == 0 || pc == 0 {
+		return 0, false
+	}
+	if level, ok := st.cache.Load(pc); ok {
+		return level.(int), level.(int) != noMatch
+	}
+
+	level := st.match(pc)
+	st.cache.Store(pc, level)
+
+	return level, level != noMatch
+}
+
+// match returns the level of the first rule that matches a call site, or noMatch
+func (st *state) match(pc uintptr) int {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := Package(frame.Function)
+	file := strings.TrimSuffix(frame.File, ".go")
+	for _, rule := range st.rules {
+		if matches(rule.Pattern, pkg) || matches(rule.Pattern, file) {
+			return rule.Level
+		}
+	}
+
+	return noMatch
+}
+
+// Package returns the import path of the package of a qualified function name
+func Package(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
+
+// matches returns true if a pattern matches the trailing segments of a path
+func matches(pattern string, name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	segments := strings.Count(pattern, "/") + 1
+	parts := strings.Split(name, "/")
+	if len(parts) < segments {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(parts[len(parts)-segments:], "/"))
+
+	return ok
+}
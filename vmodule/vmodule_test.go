@@ -0,0 +1,63 @@
+package vmodule_test
+
+import (
+	"testing"
+
+	"vincent.click/pkg/captainslog/v2/caller"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/vmodule"
+	"vincent.click/pkg/preflight"
+)
+
+func TestParse(test *testing.T) {
+	t := preflight.Unit(test)
+
+	spec, err := vmodule.Parse("app/db=trace, app/http/*=WARN,,pool.go=2")
+	t.Expect(err).Is().Nil()
+	t.Expect(spec.Rules()).Equals([]vmodule.Rule{
+		{Pattern: "app/db", Level: levels.Trace},
+		{Pattern: "app/http/*", Level: levels.Warn},
+		{Pattern: "pool", Level: levels.Info},
+	})
+	t.Expect(spec.String()).Equals("app/db=trace,app/http/*=warn,pool=info")
+
+	for _, invalid := range []string{"app/db", "=trace", "app/db=loud", "app/[=info"} {
+		_, err := vmodule.Parse(invalid)
+		t.Expect(err).Is().Not().Nil()
+	}
+}
+
+func TestLevel(test *testing.T) {
+	t := preflight.Unit(test)
+
+	pc := caller.PC(1)
+
+	// should match the package of the call site
+	spec, _ := vmodule.Parse("captainslog/*/vmodule_test=error")
+	level, ok := spec.Level(pc)
+	t.Expect(ok).Equals(true)
+	t.Expect(level).Equals(levels.Error)
+
+	// should match the file of the call site, using the first matching rule
+	t.Expect(spec.Set("engineering=trace,vmodule/vmodule_test=debug,vmodule_test=warn")).Is().Nil()
+	level, ok = spec.Level(pc)
+	t.Expect(ok).Equals(true)
+	t.Expect(level).Equals(levels.Debug)
+
+	// should not match other packages
+	t.Expect(spec.Set("enterprise/*=trace")).Is().Nil()
+	_, ok = spec.Level(pc)
+	t.Expect(ok).Equals(false)
+
+	// results should be cached
+	_, ok = spec.Level(pc)
+	t.Expect(ok).Equals(false)
+}
+
+func TestPackage(test *testing.T) {
+	t := preflight.Unit(test)
+
+	t.Expect(vmodule.Package("main.main")).Equals("main")
+	t.Expect(vmodule.Package("example.com/app/db.(*Pool).Get")).Equals("example.com/app/db")
+	t.Expect(vmodule.Package("example.com/app.v2/db.Open.func1")).Equals("example.com/app.v2/db")
+}
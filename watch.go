@@ -0,0 +1,222 @@
+package captainslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+)
+
+// Watcher reloads the configuration of the default logger from a file
+// whenever it changes. Each reload swaps the default logger atomically, so
+// messages in flight are printed once with the options they started with.
+// Hooks, filters, and other options that the configuration does not control
+// are kept.
+type Watcher struct {
+	// path of the configuration file
+	Path string
+
+	mutex  sync.Mutex
+	config Config
+	// contents of the file that were applied and last rejected
+	data     []byte
+	rejected []byte
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Watch applies a configuration file to the default logger, and then checks
+// the file for changes every interval until the watcher is stopped
+func Watch(path string, interval time.Duration) (*Watcher, error) {
+	w := &Watcher{
+		Path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	go w.poll(interval)
+
+	return w, nil
+}
+
+// Config returns the configuration that was last applied
+func (w *Watcher) Config() Config {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.config
+}
+
+// Reload reads the configuration file and applies it if it changed. An invalid
+// configuration is logged and rejected, keeping the previous one.
+func (w *Watcher) Reload() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data, err := os.ReadFile(w.Path)
+	if err == nil && w.data != nil && bytes.Equal(data, w.data) {
+		return nil
+	}
+
+	cfg := Config{}
+	if err == nil {
+		err = decode(data, &cfg)
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid config file %s: %w", w.Path, err)
+	}
+
+	next := *Default()
+	if err == nil {
+		err = cfg.apply(&next)
+	}
+	if err != nil {
+		// rejections are logged once for each version of the file
+		if w.data != nil && (w.rejected == nil || !bytes.Equal(data, w.rejected)) {
+			Default().Field("error", err).Error("rejected logging config %s", w.Path)
+		}
+		w.rejected = data
+
+		return err
+	}
+
+	// reset the loggers that are no longer named
+	for name := range w.config.Levels {
+		if _, ok := cfg.Levels[name]; !ok {
+			Get(name).SetLevel(levels.Inherit)
+		}
+	}
+
+	changes := diff(w.config, cfg)
+	first := w.data == nil
+	w.config = cfg
+	w.data = data
+	SetDefault(&next)
+	if !first && len(changes) > 0 {
+		// the changes are logged even if they are below the new level
+		m := next.Fields(changes...)
+		m.Threshold = levels.Trace
+		m.Info("reloaded logging config %s", w.Path)
+	}
+
+	return nil
+}
+
+// Stop stops checking the file for changes
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// poll reloads the configuration every interval
+func (w *Watcher) poll(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Reload()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// decode parses a configuration from JSON, rejecting unknown keys
+func decode(data []byte, cfg *Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(cfg)
+}
+
+// diff returns a field for each key that changed between configurations
+func diff(old Config, cfg Config) []msg.Field {
+	changes := []msg.Field{}
+	change := func(key string, from string, to string) {
+		if from != to {
+			changes = append(changes, msg.String(key, describe(from)+" -> "+describe(to)))
+		}
+	}
+
+	change("name", old.Name, cfg.Name)
+	change("level", old.Level, cfg.Level)
+	change("color", describeBool(old.Color), describeBool(cfg.Color))
+	change("theme", old.Theme, cfg.Theme)
+	change("labels", old.Labels, cfg.Labels)
+	change("labelWidth", describeOptionalInt(old.LabelWidth), describeOptionalInt(cfg.LabelWidth))
+	change("format", old.Format, cfg.Format)
+	change("timeFormat", old.TimeFormat, cfg.TimeFormat)
+	change("nameCutoff", describeInt(old.NameCutoff), describeInt(cfg.NameCutoff))
+	change("stdout", old.Stdout, cfg.Stdout)
+	change("stderr", old.Stderr, cfg.Stderr)
+	change("vmodule", old.VModule, cfg.VModule)
+	change("goroutine", strconv.FormatBool(old.Goroutine), strconv.FormatBool(cfg.Goroutine))
+
+	names := []string{}
+	for name := range old.Levels {
+		names = append(names, name)
+	}
+	for name := range cfg.Levels {
+		if _, ok := old.Levels[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		change("levels."+name, old.Levels[name], cfg.Levels[name])
+	}
+
+	return changes
+}
+
+// describe returns a value in a diff
+func describe(value string) string {
+	if len(value) == 0 {
+		return "default"
+	}
+
+	return value
+}
+
+// describeBool returns an optional bool in a diff
+func describeBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*value)
+}
+
+// describeInt returns an optional int in a diff
+func describeInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(value)
+}
+
+// describeOptionalInt returns an int that may be unset in a diff
+func describeOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
@@ -0,0 +1,103 @@
+package captainslog_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"vincent.click/pkg/captainslog/v2"
+	"vincent.click/pkg/captainslog/v2/levels"
+	"vincent.click/pkg/captainslog/v2/msg"
+	"vincent.click/pkg/captainslog/v2/preflight"
+)
+
+func ExampleWatch() {
+	watcher, err := captainslog.Watch("logging.json", 5*time.Second)
+	if err != nil {
+		panic(err)
+	}
+	defer watcher.Stop()
+
+	captainslog.Info("logging is configured")
+}
+
+func TestWatch(test *testing.T) {
+	t := preflight.Unit(test)
+
+	previous := captainslog.Default()
+	defer captainslog.SetDefault(previous)
+
+	dir := t.T.TempDir()
+	path := filepath.Join(dir, "logging.json")
+	logs := filepath.Join(dir, "logs.txt")
+	write := func(cfg string) {
+		cfg = strings.ReplaceAll(cfg, "$LOGS", logs)
+		t.Expect(os.WriteFile(path, []byte(cfg), 0o600)).Is().Nil()
+	}
+
+	write(`{"level": "info", "color": false, "stdout": "$LOGS", "stderr": "$LOGS", "levels": {"reliant.helm": "trace"}}`)
+	log := captainslog.NewLogger()
+	log.AddHook(func(m *msg.Message) bool {
+		return true
+	})
+	captainslog.SetDefault(log)
+
+	watcher, err := captainslog.Watch(path, 10*time.Millisecond)
+	t.Expect(err).Is().Nil()
+	defer watcher.Stop()
+
+	t.Expect(captainslog.Default().GetLevel()).Equals(levels.Info)
+	t.Expect(captainslog.Get("reliant.helm").GetLevel()).Equals(levels.Trace)
+	t.Expect(captainslog.Default().Hooks).HasLength(1)
+
+	// should apply changes to the file
+	write(`{"level": "warn", "color": false, "stdout": "$LOGS", "stderr": "$LOGS", "levels": {"reliant.tactical": "error"}}`)
+	wait(t, func() bool {
+		return captainslog.Default().GetLevel() == levels.Warn
+	})
+	t.Expect(watcher.Config().Level).Equals("warn")
+	t.Expect(captainslog.Get("reliant.helm").GetLevel()).Equals(levels.Inherit)
+	t.Expect(captainslog.Get("reliant.tactical").GetLevel()).Equals(levels.Error)
+
+	// options that are not configured should be kept
+	t.Expect(captainslog.Default().Hooks).HasLength(1)
+
+	// should reject invalid changes
+	write(`{"level": "loud"}`)
+	err = watcher.Reload()
+	t.Expect(err).Is().Not().Nil()
+	t.Expect(err.Error()).Matches("invalid config level")
+	t.Expect(captainslog.Default().GetLevel()).Equals(levels.Warn)
+	t.Expect(watcher.Config().Level).Equals("warn")
+
+	watcher.Stop()
+	written, err := os.ReadFile(logs)
+	t.Expect(err).Is().Nil()
+	lines := strings.Split(strings.TrimSuffix(string(written), "\n"), "\n")
+	t.Expect(lines).HasLength(2)
+	t.Expect(lines[0]).Matches(`^ +info :: .+ :: level="info -> warn", levels\.reliant\.helm="trace -> default", levels\.reliant\.tactical="default -> error" :: reloaded logging config .+logging\.json$`)
+	t.Expect(lines[1]).Matches(`^ +error :: .+ :: error="invalid config level: unknown log level \\"loud\\"" :: rejected logging config .+logging\.json$`)
+}
+
+func TestWatchInvalid(test *testing.T) {
+	t := preflight.Unit(test)
+
+	_, err := captainslog.Watch(filepath.Join(t.T.TempDir(), "missing.json"), time.Second)
+	t.Expect(err).Is().Not().Nil()
+}
+
+/**
+ * Test Helpers
+ */
+
+func wait(t *preflight.Test, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.T.Fatal("condition was not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}